@@ -10,8 +10,16 @@ package cache
 import (
 	"context"
 	"time"
+
+	"github.com/cockroachdb/errors"
 )
 
+// ErrNotFound key 不存在时各后端 Get/GetWithTTL 返回的错误, 可用 errors.Is 判断
+var ErrNotFound = errors.New("not found")
+
+// Cache 缓存后端接口
+// 实现方在 key 不存在或已过期时, Get/GetWithTTL 须返回包装了 ErrNotFound 的错误,
+// 调用方据此区分未命中与后端故障
 type Cache interface {
 	Get(ctx context.Context, key string) (any, error)
 	GetWithTTL(ctx context.Context, key string) (any, time.Duration, error)
@@ -31,7 +31,7 @@ func (g *GoCache) Get(_ context.Context, key string) (any, error) {
 	var err error
 	value, found := g.Client.Get(key)
 	if !found {
-		err = errors.Newf("key %s not found", key)
+		err = errors.Newf("key %s %w", key, ErrNotFound)
 	}
 	return value, err
 }
@@ -40,7 +40,7 @@ func (g *GoCache) GetWithTTL(_ context.Context, key string) (any, time.Duration,
 	var err error
 	value, t, found := g.Client.GetWithExpiration(key)
 	if !found {
-		err = errors.Newf("key %s not found", key)
+		err = errors.Newf("key %s %w", key, ErrNotFound)
 		return value, 0, err
 	}
 	return value, time.Until(t), nil
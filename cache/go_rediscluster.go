@@ -39,7 +39,7 @@ func NewGoRedisCluster(options ...Option) *GoRedisCluster {
 func (g *GoRedisCluster) Get(ctx context.Context, key string) (any, error) {
 	object, err := g.Client.Get(ctx, key).Result()
 	if err == goredis.Nil {
-		return nil, errors.Newf("key %s not found", key)
+		return nil, errors.Newf("key %s %w", key, ErrNotFound)
 	}
 	return object, err
 }
@@ -48,7 +48,7 @@ func (g *GoRedisCluster) Get(ctx context.Context, key string) (any, error) {
 func (g *GoRedisCluster) GetWithTTL(ctx context.Context, key string) (any, time.Duration, error) {
 	object, err := g.Client.Get(ctx, key).Result()
 	if err == goredis.Nil {
-		return nil, 0, errors.Newf("key %s not found", key)
+		return nil, 0, errors.Newf("key %s %w", key, ErrNotFound)
 	}
 	if err != nil {
 		return nil, 0, err
@@ -0,0 +1,48 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cockroachdb/errors"
+)
+
+// SetJSON stores value as a JSON string, readable by GetJSON on every backend.
+func SetJSON(ctx context.Context, c Cache, key string, value any, options ...Option) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, key, string(data), options...)
+}
+
+// GetJSON reads a value stored by SetJSON into out.
+// Redigo JSON-encodes values on Set, so a JSON string wrapping the payload is unwrapped first.
+func GetJSON(ctx context.Context, c Cache, key string, out any) error {
+	v, err := c.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	var data []byte
+	switch t := v.(type) {
+	case string:
+		data = []byte(t)
+	case []byte:
+		data = t
+	default:
+		return errors.Newf("key %s: unexpected value type %T", key, v)
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err == nil {
+			data = []byte(s)
+		}
+	}
+	return json.Unmarshal(data, out)
+}
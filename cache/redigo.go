@@ -62,6 +62,9 @@ func (g *Redigo) Get(_ context.Context, key string) (any, error) {
 	conn := g.Client.Get()
 	defer conn.Close()
 	reply, err := redigo.Bytes(conn.Do("GET", key))
+	if errors.Is(err, redigo.ErrNil) {
+		return nil, errors.Newf("key %s %w", key, ErrNotFound)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +76,9 @@ func (g *Redigo) GetWithTTL(_ context.Context, key string) (any, time.Duration,
 	conn := g.Client.Get()
 	defer conn.Close()
 	reply, err := redigo.Bytes(conn.Do("GET", key))
+	if errors.Is(err, redigo.ErrNil) {
+		return nil, 0, errors.Newf("key %s %w", key, ErrNotFound)
+	}
 	if err != nil {
 		return nil, 0, err
 	}
@@ -81,7 +87,7 @@ func (g *Redigo) GetWithTTL(_ context.Context, key string) (any, time.Duration,
 		return reply, 0, err
 	}
 	if ttl == -2 {
-		return nil, 0, errors.Newf("key %s not found", key)
+		return nil, 0, errors.Newf("key %s %w", key, ErrNotFound)
 	}
 	return reply, time.Duration(ttl), nil
 }
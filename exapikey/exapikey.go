@@ -0,0 +1,261 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+// Package exapikey 机器客户端 API Key 的签发、哈希存储、校验、权限范围与过期管理
+package exapikey
+
+import (
+	"context"
+	"crypto/subtle"
+	"hash/crc32"
+	"strings"
+	"time"
+
+	"github.com/ergoapi/util/common"
+	"github.com/ergoapi/util/exhash"
+	"github.com/ergoapi/util/expass"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	idLen       = 16
+	secretLen   = 32
+	checksumLen = 6
+	bodyLen     = idLen + secretLen + checksumLen
+
+	base62 = common.DIGITS + common.Alpha
+)
+
+var (
+	ErrInvalidKey = errors.New("apikey: invalid key")
+	ErrKeyRevoked = errors.New("apikey: key revoked")
+	ErrKeyExpired = errors.New("apikey: key expired")
+	ErrNotFound   = errors.New("apikey: key not found")
+)
+
+// Key API Key 记录, 只保存哈希, 明文仅在签发时返回一次
+type Key struct {
+	ID         string            `json:"id"`
+	Prefix     string            `json:"prefix"`
+	Hash       string            `json:"-"`
+	Name       string            `json:"name"`
+	Owner      string            `json:"owner"`
+	Scopes     []string          `json:"scopes,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	ExpiresAt  time.Time         `json:"expires_at,omitzero"`
+	LastUsedAt time.Time         `json:"last_used_at,omitzero"`
+	RevokedAt  time.Time         `json:"revoked_at,omitzero"`
+}
+
+// HasScopes 是否拥有全部 scope, "*" 表示全部
+func (k *Key) HasScopes(scopes ...string) bool {
+	return MatchScopes(k.Scopes, scopes...)
+}
+
+// MatchScopes granted 是否包含全部 required, granted 中的 "*" 匹配任意 scope
+func MatchScopes(granted []string, required ...string) bool {
+	for _, s := range required {
+		found := false
+		for _, have := range granted {
+			if have == s || have == "*" {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Expired 在 now 时是否已过期
+func (k *Key) Expired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !now.Before(k.ExpiresAt)
+}
+
+// Revoked 是否已吊销
+func (k *Key) Revoked() bool {
+	return !k.RevokedAt.IsZero()
+}
+
+// Store API Key 存储
+type Store interface {
+	Create(ctx context.Context, k *Key) error
+	// Get 不存在时返回 ErrNotFound
+	Get(ctx context.Context, id string) (*Key, error)
+	// List 按创建时间返回 owner 的全部 key
+	List(ctx context.Context, owner string) ([]*Key, error)
+	Revoke(ctx context.Context, id string, at time.Time) error
+	// Touch 更新最后使用时间
+	Touch(ctx context.Context, id string, at time.Time) error
+}
+
+// Options Manager 配置
+type Options struct {
+	// Prefix key 前缀, 默认 "ak_live", 测试环境可用 "ak_test"
+	Prefix string
+	// Pepper 服务端密钥, 设置后以 HMAC-SHA256 存储哈希, 否则为 SHA-256
+	Pepper string
+	// TouchInterval 最后使用时间的最小更新间隔, 默认 1 分钟, 避免每次请求都写存储
+	TouchInterval time.Duration
+	// Now 时钟, 默认 time.Now
+	Now func() time.Time
+}
+
+// Manager API Key 管理
+type Manager struct {
+	store Store
+	opts  Options
+}
+
+// New 创建 Manager
+func New(store Store, opts *Options) *Manager {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Prefix == "" {
+		o.Prefix = "ak_live"
+	}
+	if o.TouchInterval <= 0 {
+		o.TouchInterval = time.Minute
+	}
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+	return &Manager{store: store, opts: o}
+}
+
+// IssueRequest 签发参数
+type IssueRequest struct {
+	Owner    string
+	Name     string
+	Scopes   []string
+	Metadata map[string]string
+	// TTL 有效期, 0 表示永不过期
+	TTL time.Duration
+}
+
+// Issue 签发新 key, 返回明文(仅此一次)和记录
+func (m *Manager) Issue(ctx context.Context, req IssueRequest) (string, *Key, error) {
+	if req.Owner == "" {
+		return "", nil, errors.New("apikey: owner required")
+	}
+	id, err := expass.PwGenAlphaNum(idLen)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := expass.PwGenAlphaNum(secretLen)
+	if err != nil {
+		return "", nil, err
+	}
+	token := formatToken(m.opts.Prefix, id+secret)
+	now := m.opts.Now()
+	k := &Key{
+		ID:        id,
+		Prefix:    m.opts.Prefix,
+		Hash:      m.hash(token),
+		Name:      req.Name,
+		Owner:     req.Owner,
+		Scopes:    req.Scopes,
+		Metadata:  req.Metadata,
+		CreatedAt: now,
+	}
+	if req.TTL > 0 {
+		k.ExpiresAt = now.Add(req.TTL)
+	}
+	if err := m.store.Create(ctx, k); err != nil {
+		return "", nil, errors.Wrap(err, "apikey: create")
+	}
+	return token, k, nil
+}
+
+// Verify 校验 key, 返回对应记录; 格式或校验和错误不会访问存储
+func (m *Manager) Verify(ctx context.Context, token string) (*Key, error) {
+	prefix, id, ok := ParseToken(token)
+	if !ok || prefix != m.opts.Prefix {
+		return nil, ErrInvalidKey
+	}
+	k, err := m.store.Get(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrInvalidKey
+	} else if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(k.Hash), []byte(m.hash(token))) != 1 {
+		return nil, ErrInvalidKey
+	}
+	now := m.opts.Now()
+	if k.Revoked() {
+		return nil, ErrKeyRevoked
+	}
+	if k.Expired(now) {
+		return nil, ErrKeyExpired
+	}
+	if now.Sub(k.LastUsedAt) >= m.opts.TouchInterval {
+		// 最后使用时间仅供参考, 写失败不影响认证
+		if err := m.store.Touch(ctx, k.ID, now); err == nil {
+			k.LastUsedAt = now
+		}
+	}
+	return k, nil
+}
+
+// Revoke 吊销 key
+func (m *Manager) Revoke(ctx context.Context, id string) error {
+	return m.store.Revoke(ctx, id, m.opts.Now())
+}
+
+// List 列出 owner 的 key
+func (m *Manager) List(ctx context.Context, owner string) ([]*Key, error) {
+	return m.store.List(ctx, owner)
+}
+
+func (m *Manager) hash(token string) string {
+	if m.opts.Pepper == "" {
+		return exhash.GenSha256(token)
+	}
+	return exhash.HmacSha256(m.opts.Pepper, token)
+}
+
+// formatToken prefix_ + body + 校验和
+func formatToken(prefix, body string) string {
+	s := prefix + "_" + body
+	return s + checksum(s)
+}
+
+// checksum CRC32 的 base62 编码, 定长 6 位
+func checksum(s string) string {
+	n := crc32.ChecksumIEEE([]byte(s))
+	out := make([]byte, checksumLen)
+	for i := checksumLen - 1; i >= 0; i-- {
+		out[i] = base62[n%62]
+		n /= 62
+	}
+	return string(out)
+}
+
+// ParseToken 解析 key 的前缀和 ID 并检查校验和, 可用于在查库前过滤拼写错误或伪造的 key
+func ParseToken(token string) (prefix, id string, ok bool) {
+	i := strings.LastIndex(token, "_")
+	if i <= 0 || len(token)-i-1 != bodyLen {
+		return "", "", false
+	}
+	body := token[i+1:]
+	for j := 0; j < len(body); j++ {
+		if strings.IndexByte(base62, body[j]) < 0 {
+			return "", "", false
+		}
+	}
+	head := token[:len(token)-checksumLen]
+	if subtle.ConstantTimeCompare([]byte(checksum(head)), []byte(token[len(head):])) != 1 {
+		return "", "", false
+	}
+	return token[:i], body[:idLen], true
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exapikey
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStores(t *testing.T) map[string]Store {
+	sqlStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "keys.db"))
+	require.NoError(t, err)
+	return map[string]Store{"memory": NewMemoryStore(), "sqlite": sqlStore}
+}
+
+func TestParseToken(t *testing.T) {
+	token := formatToken("ak_live", strings.Repeat("a", idLen)+strings.Repeat("b", secretLen))
+	prefix, id, ok := ParseToken(token)
+	require.True(t, ok)
+	assert.Equal(t, "ak_live", prefix)
+	assert.Equal(t, strings.Repeat("a", idLen), id)
+
+	// 任意一位被修改都会导致校验和失败
+	tampered := token[:20] + "Z" + token[21:]
+	_, _, ok = ParseToken(tampered)
+	assert.False(t, ok)
+	for _, bad := range []string{"", "ak_live_", "ak_live_short", token + "x", strings.Replace(token, "a", "-", 1)} {
+		_, _, ok := ParseToken(bad)
+		assert.False(t, ok, bad)
+	}
+}
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now().Truncate(time.Millisecond)
+			m := New(store, &Options{Pepper: "pepper", Now: func() time.Time { return now }})
+
+			token, key, err := m.Issue(ctx, IssueRequest{
+				Owner:    "svc-billing",
+				Name:     "billing worker",
+				Scopes:   []string{"invoice:read", "invoice:write"},
+				Metadata: map[string]string{"env": "prod"},
+				TTL:      time.Hour,
+			})
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(token, "ak_live_"))
+			assert.NotContains(t, key.Hash, token)
+
+			got, err := m.Verify(ctx, token)
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, got.ID)
+			assert.Equal(t, "prod", got.Metadata["env"])
+			assert.True(t, got.HasScopes("invoice:read"))
+			assert.False(t, got.HasScopes("invoice:delete"))
+
+			stored, err := store.Get(ctx, key.ID)
+			require.NoError(t, err)
+			assert.True(t, stored.LastUsedAt.Equal(now))
+
+			// 同一 ID 不同 secret 的伪造 key 校验失败
+			prefix, id, _ := ParseToken(token)
+			forged := formatToken(prefix, id+strings.Repeat("x", secretLen))
+			_, err = m.Verify(ctx, forged)
+			assert.ErrorIs(t, err, ErrInvalidKey)
+			_, err = New(store, &Options{Prefix: "ak_test", Pepper: "pepper"}).Verify(ctx, token)
+			assert.ErrorIs(t, err, ErrInvalidKey)
+			_, err = New(store, &Options{Pepper: "other"}).Verify(ctx, token)
+			assert.ErrorIs(t, err, ErrInvalidKey)
+
+			now = now.Add(2 * time.Hour)
+			_, err = m.Verify(ctx, token)
+			assert.ErrorIs(t, err, ErrKeyExpired)
+
+			token2, key2, err := m.Issue(ctx, IssueRequest{Owner: "svc-billing", Scopes: []string{"*"}})
+			require.NoError(t, err)
+			_, err = m.Verify(ctx, token2)
+			require.NoError(t, err, "keys without TTL never expire")
+			require.NoError(t, m.Revoke(ctx, key2.ID))
+			_, err = m.Verify(ctx, token2)
+			assert.ErrorIs(t, err, ErrKeyRevoked)
+
+			keys, err := m.List(ctx, "svc-billing")
+			require.NoError(t, err)
+			require.Len(t, keys, 2)
+			assert.Equal(t, key.ID, keys[0].ID)
+			assert.True(t, keys[1].Revoked())
+			assert.ErrorIs(t, m.Revoke(ctx, "missing"), ErrNotFound)
+		})
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exapikey
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/glebarez/sqlite"
+	goredis "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// MemoryStore 内存存储, 适合测试和单实例
+type MemoryStore struct {
+	mu   sync.RWMutex
+	keys map[string]Key
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{keys: map[string]Key{}}
+}
+
+func (s *MemoryStore) Create(_ context.Context, k *Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.keys[k.ID]; ok {
+		return errors.Newf("apikey: duplicate id %s", k.ID)
+	}
+	s.keys[k.ID] = *k
+	return nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (*Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	k, ok := s.keys[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &k, nil
+}
+
+func (s *MemoryStore) List(_ context.Context, owner string) ([]*Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var out []*Key
+	for _, k := range s.keys {
+		if k.Owner == owner {
+			out = append(out, &k)
+		}
+	}
+	sortKeys(out)
+	return out, nil
+}
+
+func (s *MemoryStore) Revoke(_ context.Context, id string, at time.Time) error {
+	return s.update(id, func(k *Key) { k.RevokedAt = at })
+}
+
+func (s *MemoryStore) Touch(_ context.Context, id string, at time.Time) error {
+	return s.update(id, func(k *Key) { k.LastUsedAt = at })
+}
+
+func (s *MemoryStore) update(id string, fn func(*Key)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.keys[id]
+	if !ok {
+		return ErrNotFound
+	}
+	fn(&k)
+	s.keys[id] = k
+	return nil
+}
+
+func sortKeys(keys []*Key) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+}
+
+// keyRow api key 表结构
+type keyRow struct {
+	ID         string `gorm:"primaryKey;size:32"`
+	Prefix     string `gorm:"size:32"`
+	Hash       string `gorm:"size:64"`
+	Name       string `gorm:"size:255"`
+	Owner      string `gorm:"index;size:255"`
+	Scopes     string `gorm:"size:1024"`
+	Metadata   []byte
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+	RevokedAt  time.Time
+}
+
+func (keyRow) TableName() string {
+	return "api_keys"
+}
+
+func (r *keyRow) key() *Key {
+	k := &Key{
+		ID:         r.ID,
+		Prefix:     r.Prefix,
+		Hash:       r.Hash,
+		Name:       r.Name,
+		Owner:      r.Owner,
+		CreatedAt:  r.CreatedAt,
+		ExpiresAt:  r.ExpiresAt,
+		LastUsedAt: r.LastUsedAt,
+		RevokedAt:  r.RevokedAt,
+	}
+	if r.Scopes != "" {
+		k.Scopes = strings.Split(r.Scopes, " ")
+	}
+	if len(r.Metadata) > 0 {
+		_ = json.Unmarshal(r.Metadata, &k.Metadata)
+	}
+	return k
+}
+
+// SQLiteStore SQLite 存储
+type SQLiteStore struct {
+	DB *gorm.DB
+}
+
+var _ Store = (*SQLiteStore)(nil)
+
+// NewSQLiteStore 打开 dsn 并迁移表结构
+func NewSQLiteStore(dsn string) (*SQLiteStore, error) {
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		return nil, errors.Wrap(err, "apikey: open sqlite")
+	}
+	if err := db.AutoMigrate(&keyRow{}); err != nil {
+		return nil, errors.Wrap(err, "apikey: migrate sqlite")
+	}
+	return &SQLiteStore{DB: db}, nil
+}
+
+func (s *SQLiteStore) Create(ctx context.Context, k *Key) error {
+	row := keyRow{
+		ID:         k.ID,
+		Prefix:     k.Prefix,
+		Hash:       k.Hash,
+		Name:       k.Name,
+		Owner:      k.Owner,
+		Scopes:     strings.Join(k.Scopes, " "),
+		CreatedAt:  k.CreatedAt,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+	}
+	if len(k.Metadata) > 0 {
+		row.Metadata, _ = json.Marshal(k.Metadata)
+	}
+	return s.DB.WithContext(ctx).Create(&row).Error
+}
+
+func (s *SQLiteStore) Get(ctx context.Context, id string) (*Key, error) {
+	var row keyRow
+	err := s.DB.WithContext(ctx).Where("id = ?", id).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return row.key(), nil
+}
+
+func (s *SQLiteStore) List(ctx context.Context, owner string) ([]*Key, error) {
+	var rows []keyRow
+	if err := s.DB.WithContext(ctx).Where("owner = ?", owner).Order("created_at, id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*Key, 0, len(rows))
+	for i := range rows {
+		out = append(out, rows[i].key())
+	}
+	return out, nil
+}
+
+func (s *SQLiteStore) Revoke(ctx context.Context, id string, at time.Time) error {
+	return s.update(ctx, id, "revoked_at", at)
+}
+
+func (s *SQLiteStore) Touch(ctx context.Context, id string, at time.Time) error {
+	return s.update(ctx, id, "last_used_at", at)
+}
+
+func (s *SQLiteStore) update(ctx context.Context, id, column string, at time.Time) error {
+	res := s.DB.WithContext(ctx).Model(&keyRow{}).Where("id = ?", id).Update(column, at)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RedisStore Redis 存储, 每个 key 为一个 hash, 吊销和最后使用时间分字段写入, 互不覆盖
+type RedisStore struct {
+	Client goredis.UniversalClient
+	Prefix string
+}
+
+var _ Store = (*RedisStore)(nil)
+
+// NewRedisStore 创建 Redis 存储, prefix 默认 "apikey:"
+func NewRedisStore(client goredis.UniversalClient, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = "apikey:"
+	}
+	return &RedisStore{Client: client, Prefix: prefix}
+}
+
+func (s *RedisStore) keyName(id string) string {
+	return s.Prefix + "key:" + id
+}
+
+func (s *RedisStore) ownerName(owner string) string {
+	return s.Prefix + "owner:" + owner
+}
+
+func (s *RedisStore) Create(ctx context.Context, k *Key) error {
+	data := *k
+	// 可变字段单独存放
+	data.LastUsedAt, data.RevokedAt = time.Time{}, time.Time{}
+	raw, err := json.Marshal(struct {
+		Key
+		Hash string `json:"hash"`
+	}{data, k.Hash})
+	if err != nil {
+		return err
+	}
+	ok, err := s.Client.HSetNX(ctx, s.keyName(k.ID), "data", raw).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Newf("apikey: duplicate id %s", k.ID)
+	}
+	return s.Client.SAdd(ctx, s.ownerName(k.Owner), k.ID).Err()
+}
+
+func (s *RedisStore) Get(ctx context.Context, id string) (*Key, error) {
+	fields, err := s.Client.HGetAll(ctx, s.keyName(id)).Result()
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := fields["data"]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	var data struct {
+		Key
+		Hash string `json:"hash"`
+	}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return nil, errors.Wrapf(err, "apikey: decode %s", id)
+	}
+	k := data.Key
+	k.Hash = data.Hash
+	k.LastUsedAt = parseTime(fields["last_used_at"])
+	k.RevokedAt = parseTime(fields["revoked_at"])
+	return &k, nil
+}
+
+func (s *RedisStore) List(ctx context.Context, owner string) ([]*Key, error) {
+	ids, err := s.Client.SMembers(ctx, s.ownerName(owner)).Result()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Key, 0, len(ids))
+	for _, id := range ids {
+		k, err := s.Get(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		out = append(out, k)
+	}
+	sortKeys(out)
+	return out, nil
+}
+
+func (s *RedisStore) Revoke(ctx context.Context, id string, at time.Time) error {
+	return s.setField(ctx, id, "revoked_at", at)
+}
+
+func (s *RedisStore) Touch(ctx context.Context, id string, at time.Time) error {
+	return s.setField(ctx, id, "last_used_at", at)
+}
+
+func (s *RedisStore) setField(ctx context.Context, id, field string, at time.Time) error {
+	exists, err := s.Client.HExists(ctx, s.keyName(id), "data").Result()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNotFound
+	}
+	return s.Client.HSet(ctx, s.keyName(id), field, at.Format(time.RFC3339Nano)).Err()
+}
+
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
@@ -7,20 +7,10 @@
 package exctx
 
 import (
-	"bytes"
 	"context"
-	"encoding/binary"
-	"encoding/hex"
-	"fmt"
-	"math/rand"
-	"net"
-	"os"
-	"time"
 
 	"github.com/cockroachdb/errors"
 
-	"github.com/ergoapi/util/exnet"
-
 	"github.com/gin-gonic/gin"
 )
 
@@ -55,39 +45,14 @@ func NewTrace() *TraceContext {
 	return trace
 }
 
+// NewSpanID 使用全局 IDGenerator 生成 span ID
 func NewSpanID() string {
-	timestamp := uint32(time.Now().Unix())
-	ipToLong := binary.BigEndian.Uint32([]byte(exnet.LocalIPs()[0]))
-	b := bytes.Buffer{}
-	b.WriteString(fmt.Sprintf("%08x", ipToLong^timestamp))
-	b.WriteString(fmt.Sprintf("%08x", rand.Int31()))
-	return b.String()
-}
-
-func GetTraceID() (traceID string) {
-	return calcTraceID(exnet.LocalIPs()[0])
+	return currentIDGenerator().NewSpanID()
 }
 
-func calcTraceID(ip string) (traceID string) {
-	now := time.Now()
-	timestamp := uint32(now.Unix())
-	timeNano := now.UnixNano()
-	pid := os.Getpid()
-
-	b := bytes.Buffer{}
-	netIP := net.ParseIP(ip)
-	if netIP == nil {
-		b.WriteString("00000000")
-	} else {
-		b.WriteString(hex.EncodeToString(netIP.To4()))
-	}
-	b.WriteString(fmt.Sprintf("%08x", timestamp&0xffffffff))
-	b.WriteString(fmt.Sprintf("%04x", timeNano&0xffff))
-	b.WriteString(fmt.Sprintf("%04x", pid&0xffff))
-	b.WriteString(fmt.Sprintf("%06x", rand.Int31n(1<<24)))
-	b.WriteString("b0") // 末两位标记来源,b0为go
-
-	return b.String()
+// GetTraceID 使用全局 IDGenerator 生成 trace ID
+func GetTraceID() string {
+	return currentIDGenerator().NewTraceID()
 }
 
 func GetTraceContext(ctx context.Context) *TraceContext {
@@ -0,0 +1,311 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exctx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// LogrusExporter 每个 span 输出一条日志
+type LogrusExporter struct {
+	Logger *logrus.Logger
+	Level  logrus.Level
+}
+
+// NewLogrusExporter 创建 LogrusExporter, logger 为 nil 时使用 logrus 标准 logger, 级别为 Debug
+func NewLogrusExporter(logger *logrus.Logger) *LogrusExporter {
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+	return &LogrusExporter{Logger: logger, Level: logrus.DebugLevel}
+}
+
+func (e *LogrusExporter) ExportSpan(s *SpanData) {
+	fields := logrus.Fields{
+		"traceID":     s.TraceID,
+		"SpanID":      s.SpanID,
+		"span":        s.Name,
+		"duration_ms": float64(s.Duration().Microseconds()) / 1000,
+		"Tag":         "span",
+	}
+	if s.ParentSpanID != "" {
+		fields["parentSpanID"] = s.ParentSpanID
+	}
+	for k, v := range s.Attributes {
+		fields["attr."+k] = v
+	}
+	level := e.Level
+	if s.StatusCode == StatusError {
+		fields["error"] = s.StatusMessage
+		level = logrus.WarnLevel
+	}
+	e.Logger.WithFields(fields).Log(level, "span finished")
+}
+
+// MemoryExporter 在内存中保存 span, 用于测试
+type MemoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+// NewMemoryExporter 创建 MemoryExporter
+func NewMemoryExporter() *MemoryExporter {
+	return &MemoryExporter{}
+}
+
+func (e *MemoryExporter) ExportSpan(s *SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, *s)
+}
+
+// Spans 按结束顺序返回已导出的 span
+func (e *MemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]SpanData(nil), e.spans...)
+}
+
+// Reset 清空
+func (e *MemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
+
+// OTLPOptions OTLPExporter 配置
+type OTLPOptions struct {
+	// Endpoint OTLP/HTTP traces 地址, 默认 http://localhost:4318/v1/traces
+	Endpoint string
+	// Headers 附加请求头, 例如鉴权
+	Headers map[string]string
+	// ServiceName resource 的 service.name
+	ServiceName string
+	// BatchSize 达到该数量立即发送, 默认 128
+	BatchSize int
+	// FlushInterval 定时发送间隔, 默认 5s
+	FlushInterval time.Duration
+	// MaxQueue 队列上限, 超出后丢弃新 span, 默认 2048
+	MaxQueue int
+	// Client 默认超时 10s 的 http.Client
+	Client *http.Client
+}
+
+// OTLPExporter 以 OTLP/HTTP JSON 批量发送 span, 发送在后台进行, 失败只记录日志
+type OTLPExporter struct {
+	opts    OTLPOptions
+	mu      sync.Mutex
+	queue   []SpanData
+	dropped int
+	kick    chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// NewOTLPExporter 创建并启动 OTLPExporter, 退出前需调用 Shutdown
+func NewOTLPExporter(opts OTLPOptions) *OTLPExporter {
+	if opts.Endpoint == "" {
+		opts.Endpoint = "http://localhost:4318/v1/traces"
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 128
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	if opts.MaxQueue <= 0 {
+		opts.MaxQueue = 2048
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	e := &OTLPExporter{
+		opts:    opts,
+		kick:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go e.loop()
+	return e
+}
+
+func (e *OTLPExporter) ExportSpan(s *SpanData) {
+	e.mu.Lock()
+	if len(e.queue) >= e.opts.MaxQueue {
+		e.dropped++
+		e.mu.Unlock()
+		return
+	}
+	e.queue = append(e.queue, *s)
+	full := len(e.queue) >= e.opts.BatchSize
+	e.mu.Unlock()
+	if full {
+		select {
+		case e.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Dropped 因队列满被丢弃的 span 数
+func (e *OTLPExporter) Dropped() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.dropped
+}
+
+func (e *OTLPExporter) loop() {
+	defer close(e.stopped)
+	ticker := time.NewTicker(e.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.done:
+			return
+		case <-ticker.C:
+		case <-e.kick:
+		}
+		if err := e.Flush(context.Background()); err != nil {
+			logrus.Warnf("exctx: otlp export failed: %v", err)
+		}
+	}
+}
+
+// Flush 立即发送队列中的 span
+func (e *OTLPExporter) Flush(ctx context.Context) error {
+	for {
+		e.mu.Lock()
+		n := min(len(e.queue), e.opts.BatchSize)
+		batch := e.queue[:n:n]
+		e.queue = e.queue[n:]
+		e.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+		if err := e.send(ctx, batch); err != nil {
+			return err
+		}
+	}
+}
+
+// Shutdown 停止后台发送并发送剩余 span
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	e.once.Do(func() { close(e.done) })
+	select {
+	case <-e.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return e.Flush(ctx)
+}
+
+func (e *OTLPExporter) send(ctx context.Context, spans []SpanData) error {
+	body, err := json.Marshal(otlpRequest(e.opts.ServiceName, spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.opts.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.opts.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return errors.Newf("otlp collector returned %s", resp.Status)
+	}
+	return nil
+}
+
+// OTLP JSON 编码, 参见 opentelemetry-proto 的 JSON 映射: trace/span ID 为 hex, 64 位整数为字符串
+
+type otlpKeyValue struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+func otlpAttributes(attrs map[string]any) []otlpKeyValue {
+	out := make([]otlpKeyValue, 0, len(attrs))
+	for k, v := range attrs {
+		var value map[string]any
+		switch t := v.(type) {
+		case string:
+			value = map[string]any{"stringValue": t}
+		case bool:
+			value = map[string]any{"boolValue": t}
+		case int:
+			value = map[string]any{"intValue": strconv.FormatInt(int64(t), 10)}
+		case int64:
+			value = map[string]any{"intValue": strconv.FormatInt(t, 10)}
+		case float64:
+			value = map[string]any{"doubleValue": t}
+		default:
+			value = map[string]any{"stringValue": fmt.Sprint(t)}
+		}
+		out = append(out, otlpKeyValue{Key: k, Value: value})
+	}
+	return out
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func otlpRequest(service string, spans []SpanData) map[string]any {
+	items := make([]map[string]any, 0, len(spans))
+	for _, s := range spans {
+		events := make([]map[string]any, 0, len(s.Events))
+		for _, ev := range s.Events {
+			events = append(events, map[string]any{
+				"name":         ev.Name,
+				"timeUnixNano": unixNano(ev.Time),
+				"attributes":   otlpAttributes(ev.Attributes),
+			})
+		}
+		item := map[string]any{
+			"traceId":           s.TraceID,
+			"spanId":            s.SpanID,
+			"name":              s.Name,
+			"kind":              int(s.Kind),
+			"startTimeUnixNano": unixNano(s.StartTime),
+			"endTimeUnixNano":   unixNano(s.EndTime),
+			"attributes":        otlpAttributes(s.Attributes),
+			"events":            events,
+			"status":            map[string]any{"code": int(s.StatusCode), "message": s.StatusMessage},
+		}
+		if s.ParentSpanID != "" {
+			item["parentSpanId"] = s.ParentSpanID
+		}
+		items = append(items, item)
+	}
+	return map[string]any{
+		"resourceSpans": []any{map[string]any{
+			"resource": map[string]any{"attributes": otlpAttributes(map[string]any{"service.name": service})},
+			"scopeSpans": []any{map[string]any{
+				"scope": map[string]any{"name": "github.com/ergoapi/util/exctx"},
+				"spans": items,
+			}},
+		}},
+	}
+}
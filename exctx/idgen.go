@@ -0,0 +1,142 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exctx
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// IDGenerator 生成 trace ID(32 位小写 hex) 和 span ID(16 位小写 hex), 需并发安全, 不能返回全 0
+type IDGenerator interface {
+	NewTraceID() string
+	NewSpanID() string
+}
+
+var idGenerator atomic.Pointer[IDGenerator]
+
+func init() {
+	SetIDGenerator(nil)
+}
+
+// SetIDGenerator 设置全局 ID 生成器, nil 恢复默认(NewIDGenerator(false))
+func SetIDGenerator(g IDGenerator) {
+	if g == nil {
+		g = NewIDGenerator(false)
+	}
+	idGenerator.Store(&g)
+}
+
+func currentIDGenerator() IDGenerator {
+	return *idGenerator.Load()
+}
+
+// hostEntropy 进程级主机标识, 仅计算一次: 主机名、首个非 loopback IP 与 pid 的 FNV 哈希,
+// 都取不到时为随机值, 不会因无网卡而失败
+var hostEntropy = sync.OnceValue(func() uint32 {
+	h := fnv.New32a()
+	if name, err := os.Hostname(); err == nil {
+		h.Write([]byte(name))
+	}
+	if ip := firstHostIP(); ip != nil {
+		h.Write(ip)
+	}
+	_ = binary.Write(h, binary.BigEndian, uint32(os.Getpid()))
+	if v := h.Sum32(); v != 0 {
+		return v
+	}
+	return rand.Uint32() | 1
+})
+
+func firstHostIP() net.IP {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && !ipnet.IP.IsLoopback() {
+			return ipnet.IP
+		}
+	}
+	return nil
+}
+
+// hostIDGenerator 默认生成器, trace ID 为 主机标识(4 字节) + 秒级时间戳(4 字节) + 随机数(7 字节) + 0xb0(来源标记, b0 为 go),
+// span ID 为 8 字节随机数
+type hostIDGenerator struct {
+	fast bool
+}
+
+// NewIDGenerator 创建默认生成器, fast 为 true 时随机数取自 math/rand/v2, 否则取自 crypto/rand
+func NewIDGenerator(fast bool) IDGenerator {
+	return &hostIDGenerator{fast: fast}
+}
+
+func (g *hostIDGenerator) read(b []byte) {
+	if !g.fast {
+		_, _ = crand.Read(b)
+		return
+	}
+	for i := 0; i < len(b); i += 8 {
+		var v [8]byte
+		binary.LittleEndian.PutUint64(v[:], rand.Uint64())
+		copy(b[i:], v[:])
+	}
+}
+
+func (g *hostIDGenerator) NewTraceID() string {
+	var b [16]byte
+	binary.BigEndian.PutUint32(b[0:4], hostEntropy())
+	binary.BigEndian.PutUint32(b[4:8], uint32(time.Now().Unix()))
+	g.read(b[8:15])
+	b[15] = 0xb0
+	return hex.EncodeToString(b[:])
+}
+
+func (g *hostIDGenerator) NewSpanID() string {
+	var b [8]byte
+	for {
+		g.read(b[:])
+		if binary.BigEndian.Uint64(b[:]) != 0 {
+			return hex.EncodeToString(b[:])
+		}
+	}
+}
+
+// FakeIDGenerator 按序号生成 ID, 用于测试中断言固定的 trace/span ID
+type FakeIDGenerator struct {
+	trace atomic.Uint64
+	span  atomic.Uint64
+}
+
+// NewFakeIDGenerator 创建 FakeIDGenerator, 第一个 ID 为 ...01
+func NewFakeIDGenerator() *FakeIDGenerator {
+	return &FakeIDGenerator{}
+}
+
+func (g *FakeIDGenerator) NewTraceID() string {
+	return fmt.Sprintf("%032x", g.trace.Add(1))
+}
+
+func (g *FakeIDGenerator) NewSpanID() string {
+	return fmt.Sprintf("%016x", g.span.Add(1))
+}
+
+// Reset 重置序号
+func (g *FakeIDGenerator) Reset() {
+	g.trace.Store(0)
+	g.span.Store(0)
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exctx
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDGenerator(t *testing.T) {
+	for _, fast := range []bool{false, true} {
+		g := NewIDGenerator(fast)
+		seen := map[string]bool{}
+		for i := 0; i < 1000; i++ {
+			traceID, spanID := g.NewTraceID(), g.NewSpanID()
+			require.True(t, isHexID(traceID, 32), traceID)
+			require.True(t, isHexID(spanID, 16), spanID)
+			assert.Equal(t, "b0", traceID[30:])
+			assert.False(t, seen[traceID] || seen[spanID])
+			seen[traceID], seen[spanID] = true, true
+		}
+	}
+	// 主机标识只计算一次, 同一进程内前 8 位不变
+	g := NewIDGenerator(false)
+	assert.Equal(t, g.NewTraceID()[:8], GetTraceID()[:8])
+}
+
+func TestIDGeneratorConcurrent(t *testing.T) {
+	g := NewIDGenerator(true)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.True(t, isHexID(g.NewSpanID(), 16))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestFakeIDGenerator(t *testing.T) {
+	fake := NewFakeIDGenerator()
+	SetIDGenerator(fake)
+	defer SetIDGenerator(nil)
+
+	tc := NewTrace()
+	assert.Equal(t, "00000000000000000000000000000001", tc.TraceID)
+	assert.Equal(t, "0000000000000001", tc.SpanID)
+
+	_, span := StartSpan(context.Background(), "op")
+	assert.Equal(t, "00000000000000000000000000000002", span.TraceID())
+	assert.Equal(t, "0000000000000002", span.SpanID())
+
+	fake.Reset()
+	assert.Equal(t, "0000000000000001", NewSpanID())
+
+	SetIDGenerator(nil)
+	assert.NotEqual(t, "0000000000000002", NewSpanID())
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exctx
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+var logFieldsKey = contextKey("log-fields")
+
+// 日志字段名, 与 exgin、glog 已有字段保持一致
+const (
+	FieldTraceID     = "traceID"
+	FieldSpanID      = "SpanID"
+	FieldChildSpanID = "childSpanID"
+	FieldUser        = "user"
+	FieldTenant      = "tenant"
+)
+
+// WithFields 返回携带额外日志字段的 ctx, 与已有字段合并, 同名覆盖; 不修改父 ctx 中的字段
+func WithFields(ctx context.Context, fields map[string]any) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	prev := ctxFields(ctx)
+	merged := make(map[string]any, len(prev)+len(fields))
+	for k, v := range prev {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, logFieldsKey, merged)
+}
+
+// WithField 同 WithFields, 设置单个字段
+func WithField(ctx context.Context, key string, value any) context.Context {
+	return WithFields(ctx, map[string]any{key: value})
+}
+
+// WithUser 设置当前用户, 日志字段为 user
+func WithUser(ctx context.Context, user string) context.Context {
+	return WithField(ctx, FieldUser, user)
+}
+
+// WithTenant 设置当前租户, 日志字段为 tenant
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return WithField(ctx, FieldTenant, tenant)
+}
+
+// ctxFields 取 WithFields 设置的字段; gin.Context 未开启 ContextWithFallback 时从 Request 中取
+func ctxFields(ctx context.Context) map[string]any {
+	if ctx == nil {
+		return nil
+	}
+	if fields, ok := ctx.Value(logFieldsKey).(map[string]any); ok {
+		return fields
+	}
+	if ginCtx, ok := ctx.(*gin.Context); ok && ginCtx.Request != nil {
+		fields, _ := ginCtx.Request.Context().Value(logFieldsKey).(map[string]any)
+		return fields
+	}
+	return nil
+}
+
+// LogFields 返回 ctx 中的日志字段: trace ID、span ID(仅当 ctx 中已有 trace 时)以及 WithFields 设置的字段
+func LogFields(ctx context.Context) logrus.Fields {
+	fields := logrus.Fields{}
+	if ctx == nil {
+		return fields
+	}
+	tc, ok := traceFromContext(ctx)
+	if !ok {
+		if ginCtx, isGin := ctx.(*gin.Context); isGin && ginCtx.Request != nil {
+			tc, ok = traceFromContext(ginCtx.Request.Context())
+		}
+	}
+	if ok && tc != nil {
+		fields[FieldTraceID] = tc.TraceID
+		if tc.SpanID != "" {
+			fields[FieldSpanID] = tc.SpanID
+		}
+		if tc.CSpanID != "" {
+			fields[FieldChildSpanID] = tc.CSpanID
+		}
+	}
+	for k, v := range ctxFields(ctx) {
+		fields[k] = v
+	}
+	return fields
+}
+
+// Logger 返回带 ctx 日志字段的 logrus 标准 logger entry, 业务代码无需层层传递 logger
+func Logger(ctx context.Context) *logrus.Entry {
+	entry := logrus.NewEntry(logrus.StandardLogger())
+	if ctx == nil {
+		return entry
+	}
+	return entry.WithContext(ctx).WithFields(LogFields(ctx))
+}
+
+// ContextHook logrus hook, 为带 ctx 的日志(logrus.WithContext)补充 LogFields, 不覆盖已有字段
+type ContextHook struct{}
+
+// NewContextHook 创建 ContextHook, 通过 logrus.AddHook 注册
+func NewContextHook() *ContextHook {
+	return &ContextHook{}
+}
+
+func (h *ContextHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *ContextHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	for k, v := range LogFields(entry.Context) {
+		if _, exists := entry.Data[k]; !exists {
+			entry.Data[k] = v
+		}
+	}
+	return nil
+}
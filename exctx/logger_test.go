@@ -0,0 +1,74 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exctx
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogFields(t *testing.T) {
+	assert.Empty(t, LogFields(context.Background()))
+
+	ctx, span := StartSpan(context.Background(), "op")
+	ctx = WithUser(WithTenant(ctx, "acme"), "alice")
+	child := WithFields(ctx, map[string]any{"order": 42, "user": "bob"})
+
+	fields := LogFields(ctx)
+	assert.Equal(t, span.TraceID(), fields[FieldTraceID])
+	assert.Equal(t, span.SpanID(), fields[FieldSpanID])
+	assert.Equal(t, "alice", fields[FieldUser])
+	assert.Equal(t, "acme", fields[FieldTenant])
+	assert.NotContains(t, fields, "order")
+
+	fields = LogFields(child)
+	assert.Equal(t, "bob", fields[FieldUser])
+	assert.Equal(t, 42, fields["order"])
+	assert.Equal(t, "acme", fields[FieldTenant])
+}
+
+func TestLogFieldsGinContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	tc := &TraceContext{}
+	tc.TraceID = "trace-1"
+	c.Request = c.Request.WithContext(WithTenant(SetTraceContext(c.Request.Context(), tc), "acme"))
+
+	fields := LogFields(c)
+	assert.Equal(t, "trace-1", fields[FieldTraceID])
+	assert.NotContains(t, fields, FieldSpanID)
+	assert.Equal(t, "acme", fields[FieldTenant])
+}
+
+func TestLoggerAndContextHook(t *testing.T) {
+	hook := test.NewGlobal()
+	defer hook.Reset()
+
+	ctx, span := StartSpan(context.Background(), "op")
+	ctx = WithUser(ctx, "alice")
+
+	Logger(ctx).Info("hello")
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, span.TraceID(), entry.Data[FieldTraceID])
+	assert.Equal(t, "alice", entry.Data[FieldUser])
+
+	logrus.AddHook(NewContextHook())
+	defer logrus.StandardLogger().ReplaceHooks(logrus.LevelHooks{})
+	logrus.WithContext(ctx).WithField("user", "explicit").Warn("via hook")
+	entry = hook.LastEntry()
+	assert.Equal(t, span.SpanID(), entry.Data[FieldSpanID])
+	assert.Equal(t, "explicit", entry.Data[FieldUser])
+}
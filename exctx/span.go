@@ -0,0 +1,329 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exctx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var spanKey = contextKey("span")
+
+// SpanKind span 类型, 取值与 OTLP 一致
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+// StatusCode span 状态, 取值与 OTLP 一致
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// SpanEvent span 内的时间点事件
+type SpanEvent struct {
+	Name       string
+	Time       time.Time
+	Attributes map[string]any
+}
+
+// SpanData 结束后交给 Exporter 的只读快照
+type SpanData struct {
+	TraceID       string
+	SpanID        string
+	ParentSpanID  string
+	Name          string
+	Kind          SpanKind
+	StartTime     time.Time
+	EndTime       time.Time
+	Attributes    map[string]any
+	Events        []SpanEvent
+	StatusCode    StatusCode
+	StatusMessage string
+}
+
+// Duration span 耗时
+func (d *SpanData) Duration() time.Duration {
+	return d.EndTime.Sub(d.StartTime)
+}
+
+// Exporter 接收已结束的 span, 需并发安全且不应阻塞
+type Exporter interface {
+	ExportSpan(span *SpanData)
+}
+
+var (
+	exportersMu sync.RWMutex
+	exporters   []Exporter
+)
+
+// SetExporters 设置全局 exporter, 替换已有配置, 不传参数表示关闭导出
+func SetExporters(exps ...Exporter) {
+	exportersMu.Lock()
+	defer exportersMu.Unlock()
+	exporters = exps
+}
+
+// TracingEnabled 是否配置了 exporter, 可用于跳过构造 span 属性的开销
+func TracingEnabled() bool {
+	exportersMu.RLock()
+	defer exportersMu.RUnlock()
+	return len(exporters) > 0
+}
+
+func export(d *SpanData) {
+	exportersMu.RLock()
+	exps := exporters
+	exportersMu.RUnlock()
+	for _, e := range exps {
+		e.ExportSpan(d)
+	}
+}
+
+// Span 一次操作的计时与属性, 通过 StartSpan 创建, 调用 End 结束并导出
+type Span struct {
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+type spanConfig struct {
+	kind       SpanKind
+	start      time.Time
+	attributes map[string]any
+}
+
+// SpanOption StartSpan 选项
+type SpanOption func(*spanConfig)
+
+// WithSpanKind 设置 span 类型, 默认 SpanKindInternal
+func WithSpanKind(kind SpanKind) SpanOption {
+	return func(c *spanConfig) { c.kind = kind }
+}
+
+// WithStartTime 指定开始时间, 用于事后补记 span
+func WithStartTime(t time.Time) SpanOption {
+	return func(c *spanConfig) { c.start = t }
+}
+
+// WithAttributes 设置初始属性
+func WithAttributes(attrs map[string]any) SpanOption {
+	return func(c *spanConfig) {
+		for k, v := range attrs {
+			c.attributes[k] = v
+		}
+	}
+}
+
+// StartSpan 开始一个 span. 父 span 依次取自 ctx 中的 Span 和 TraceContext(例如来自请求头),
+// 都没有时开启新的 trace. 返回的 ctx 携带新 span, GetTraceContext 返回的 SpanID 也随之更新
+func StartSpan(ctx context.Context, name string, opts ...SpanOption) (context.Context, *Span) {
+	cfg := &spanConfig{kind: SpanKindInternal, attributes: map[string]any{}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.start.IsZero() {
+		cfg.start = time.Now()
+	}
+
+	s := &Span{data: SpanData{
+		SpanID:     NewSpanID(),
+		Name:       name,
+		Kind:       cfg.kind,
+		StartTime:  cfg.start,
+		Attributes: cfg.attributes,
+	}}
+	parent := &TraceContext{}
+	if p := SpanFromContext(ctx); p != nil {
+		s.data.TraceID, s.data.ParentSpanID = p.data.TraceID, p.data.SpanID
+	} else if tc, ok := traceFromContext(ctx); ok {
+		parent = tc
+		s.data.TraceID = NormalizeTraceID(tc.TraceID)
+		if isHexID(tc.SpanID, 16) {
+			s.data.ParentSpanID = tc.SpanID
+		}
+	}
+	if s.data.TraceID == "" {
+		s.data.TraceID = GetTraceID()
+	}
+
+	// 保留上游的 Caller 等信息, 仅替换为当前 span
+	tc := &TraceContext{Trace: parent.Trace}
+	tc.TraceID, tc.SpanID, tc.CSpanID = s.data.TraceID, s.data.SpanID, ""
+	ctx = context.WithValue(SetTraceContext(ctx, tc), spanKey, s)
+	return ctx, s
+}
+
+// SpanFromContext 取 ctx 中当前的 span, 没有时返回 nil
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(spanKey).(*Span)
+	return s
+}
+
+// traceFromContext 取 ctx 中已有的 TraceContext, 与 GetTraceContext 不同, 不存在时不新建
+func traceFromContext(ctx context.Context) (*TraceContext, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	if ginCtx, ok := ctx.(*gin.Context); ok {
+		if v, exists := ginCtx.Get(traceKey.String()); exists {
+			tc, ok := v.(*TraceContext)
+			return tc, ok
+		}
+	}
+	tc, ok := ctx.Value(traceKey).(*TraceContext)
+	return tc, ok
+}
+
+// TraceID 所属 trace ID
+func (s *Span) TraceID() string {
+	return s.data.TraceID
+}
+
+// SpanID span ID
+func (s *Span) SpanID() string {
+	return s.data.SpanID
+}
+
+// ParentSpanID 父 span ID, 根 span 为空
+func (s *Span) ParentSpanID() string {
+	return s.data.ParentSpanID
+}
+
+// Traceparent W3C traceparent 头的值
+func (s *Span) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-01", s.data.TraceID, s.data.SpanID)
+}
+
+// SetName 修改 span 名称, 例如路由匹配后改为路由模板
+func (s *Span) SetName(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Name = name
+}
+
+// SetAttribute 设置属性
+func (s *Span) SetAttribute(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.data.Attributes[key] = value
+}
+
+// SetAttributes 批量设置属性
+func (s *Span) SetAttributes(attrs map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	for k, v := range attrs {
+		s.data.Attributes[k] = v
+	}
+}
+
+// AddEvent 记录事件
+func (s *Span) AddEvent(name string, attrs map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.data.Events = append(s.data.Events, SpanEvent{Name: name, Time: time.Now(), Attributes: attrs})
+}
+
+// SetStatus 设置状态
+func (s *Span) SetStatus(code StatusCode, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.data.StatusCode, s.data.StatusMessage = code, message
+}
+
+// RecordError 记录 exception 事件并将状态置为 StatusError, err 为 nil 时忽略
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.AddEvent("exception", map[string]any{"exception.message": err.Error(), "exception.type": fmt.Sprintf("%T", err)})
+	s.SetStatus(StatusError, err.Error())
+}
+
+// End 结束 span 并导出, 重复调用无效
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = time.Now()
+	d := s.data
+	s.mu.Unlock()
+	export(&d)
+}
+
+// ParseTraceparent 解析 W3C traceparent 头, 返回可作为远端父 span 的 TraceContext
+func ParseTraceparent(header string) (*TraceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return nil, false
+	}
+	if !isHexID(parts[1], 32) || !isHexID(parts[2], 16) || len(parts[3]) != 2 {
+		return nil, false
+	}
+	tc := &TraceContext{}
+	tc.TraceID, tc.SpanID = parts[1], parts[2]
+	return tc, true
+}
+
+// NormalizeTraceID 转换为 W3C trace ID(32 位小写 hex), 去掉 UUID 中的 "-"; 无法转换时返回空
+func NormalizeTraceID(id string) string {
+	id = strings.ToLower(strings.ReplaceAll(id, "-", ""))
+	if !isHexID(id, 32) {
+		return ""
+	}
+	return id
+}
+
+// isHexID 是否为指定长度且非全 0 的小写 hex
+func isHexID(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	zero := true
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '0':
+		case c >= '1' && c <= '9', c >= 'a' && c <= 'f':
+			zero = false
+		default:
+			return false
+		}
+	}
+	return !zero
+}
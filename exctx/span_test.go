@@ -0,0 +1,129 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exctx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartSpan(t *testing.T) {
+	exp := NewMemoryExporter()
+	SetExporters(exp)
+	defer SetExporters()
+
+	ctx, root := StartSpan(context.Background(), "root", WithAttributes(map[string]any{"k": "v"}))
+	assert.True(t, isHexID(root.TraceID(), 32))
+	assert.True(t, isHexID(root.SpanID(), 16))
+	assert.Empty(t, root.ParentSpanID())
+	assert.Equal(t, root.SpanID(), GetTraceContext(ctx).SpanID)
+
+	_, child := StartSpan(ctx, "child")
+	assert.Equal(t, root.TraceID(), child.TraceID())
+	assert.Equal(t, root.SpanID(), child.ParentSpanID())
+	child.AddEvent("cache miss", map[string]any{"key": "a"})
+	child.RecordError(errors.New("boom"))
+	child.End()
+	child.End()
+	root.SetStatus(StatusOK, "")
+	root.End()
+	root.SetAttribute("late", true)
+
+	spans := exp.Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, StatusError, spans[0].StatusCode)
+	require.Len(t, spans[0].Events, 2)
+	assert.Equal(t, "exception", spans[0].Events[1].Name)
+	assert.Equal(t, map[string]any{"k": "v"}, spans[1].Attributes)
+	assert.False(t, spans[1].EndTime.Before(spans[1].StartTime))
+}
+
+func TestRemoteParent(t *testing.T) {
+	parent, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.True(t, ok)
+	_, span := StartSpan(SetTraceContext(context.Background(), parent), "server")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID())
+	assert.Equal(t, "00f067aa0ba902b7", span.ParentSpanID())
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+span.SpanID()+"-01", span.Traceparent())
+
+	for _, bad := range []string{"", "00-xyz-00f067aa0ba902b7-01", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"} {
+		_, ok := ParseTraceparent(bad)
+		assert.False(t, ok, bad)
+	}
+
+	// exTraceID 生成的 UUID 可直接作为 trace ID
+	assert.Equal(t, "0f8fad5bd9cb469fa16570867728950e", NormalizeTraceID("0F8FAD5B-D9CB-469F-A165-70867728950E"))
+	legacy := &TraceContext{}
+	legacy.TraceID = "not-a-w3c-id"
+	_, span = StartSpan(SetTraceContext(context.Background(), legacy), "server")
+	assert.True(t, isHexID(span.TraceID(), 32))
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []map[string]any
+	)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		body, _ := io.ReadAll(r.Body)
+		var req map[string]any
+		assert.NoError(t, json.Unmarshal(body, &req))
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+	}))
+	defer collector.Close()
+
+	exp := NewOTLPExporter(OTLPOptions{
+		Endpoint:      collector.URL + "/v1/traces",
+		Headers:       map[string]string{"X-Api-Key": "secret"},
+		ServiceName:   "demo",
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+	SetExporters(exp)
+	defer SetExporters()
+
+	ctx, root := StartSpan(context.Background(), "root", WithSpanKind(SpanKindServer))
+	_, child := StartSpan(ctx, "child", WithAttributes(map[string]any{"n": 1, "ok": true}))
+	child.End()
+	root.End()
+	_, extra := StartSpan(context.Background(), "extra")
+	extra.End()
+	require.NoError(t, exp.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	var spans []any
+	for _, req := range requests {
+		rs := req["resourceSpans"].([]any)[0].(map[string]any)
+		service := rs["resource"].(map[string]any)["attributes"].([]any)[0].(map[string]any)
+		assert.Equal(t, "demo", service["value"].(map[string]any)["stringValue"])
+		spans = append(spans, rs["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)...)
+	}
+	require.Len(t, spans, 3)
+	first := spans[0].(map[string]any)
+	assert.Equal(t, "child", first["name"])
+	assert.Equal(t, root.SpanID(), first["parentSpanId"])
+	assert.Equal(t, root.TraceID(), first["traceId"])
+	assert.IsType(t, "", first["startTimeUnixNano"])
+	assert.Equal(t, float64(SpanKindServer), spans[1].(map[string]any)["kind"])
+}
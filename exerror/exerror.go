@@ -11,6 +11,8 @@ import "fmt"
 
 type ErgoError struct {
 	Message string
+	// Code 可选的 HTTP 状态码, 为 0 时由调用方决定(exgin 默认 400)
+	Code int
 }
 
 func (ee *ErgoError) Error() string {
@@ -25,6 +27,11 @@ func Bomb(format string, args ...any) {
 	panic(ErgoError{Message: fmt.Sprintf(format, args...)})
 }
 
+// BombCode 与 Bomb 相同, 但附带 HTTP 状态码, 例如 401/403
+func BombCode(code int, format string, args ...any) {
+	panic(ErgoError{Message: fmt.Sprintf(format, args...), Code: code})
+}
+
 func Dangerous(v any) {
 	if v == nil {
 		return
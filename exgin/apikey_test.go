@@ -0,0 +1,73 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ergoapi/util/exapikey"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := exapikey.New(exapikey.NewMemoryStore(), nil)
+	token, key, err := m.Issue(context.Background(), exapikey.IssueRequest{Owner: "svc-1", Name: "worker", Scopes: []string{"read"}})
+	require.NoError(t, err)
+
+	r := gin.New()
+	api := r.Group("/api", APIKeyAuth(&APIKeyAuthOptions{Manager: m}))
+	api.GET("/read", func(c *gin.Context) {
+		claims, _ := GetAuthClaims(c)
+		SucessResponse(c, claims.Subject+"|"+APIKeyRateLimitKey(c))
+	})
+	api.GET("/write", RequirePermissions("write"), func(c *gin.Context) { SucessResponse(c, "ok") })
+
+	do := func(path string, header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("/api/read", "X-API-Key", token)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "svc-1|apikey:"+key.ID)
+	assert.Equal(t, http.StatusOK, do("/api/read", "Authorization", "Bearer "+token).Code)
+	assert.Equal(t, http.StatusForbidden, do("/api/write", "X-API-Key", token).Code)
+	// "*" key 同时通过 APIKeyAuth 的 scope 检查和 RequirePermissions
+	admin, _, err := m.Issue(context.Background(), exapikey.IssueRequest{Owner: "svc-2", Scopes: []string{"*"}})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, do("/api/write", "X-API-Key", admin).Code)
+	assert.Equal(t, http.StatusUnauthorized, do("/api/read", "", "").Code)
+	last := "0"
+	if token[len(token)-1] == '0' {
+		last = "1"
+	}
+	assert.Equal(t, http.StatusUnauthorized, do("/api/read", "X-API-Key", token[:len(token)-1]+last).Code)
+
+	require.NoError(t, m.Revoke(context.Background(), key.ID))
+	assert.Equal(t, http.StatusUnauthorized, do("/api/read", "X-API-Key", token).Code)
+}
+
+func TestAPIKeyRateLimitKeyWithoutKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	// 响应头可被 handler 任意设置, 不能作为限流 key
+	c.Header("X-Forwarded-For", "203.0.113.9")
+	assert.Equal(t, "rl:192.0.2.1", APIKeyRateLimitKey(c, "rl:"))
+}
@@ -0,0 +1,243 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ergoapi/util/exapikey"
+	"github.com/ergoapi/util/exctx"
+	"github.com/ergoapi/util/exjwt"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const authClaimsKey = "ex-auth-claims"
+
+// AuthClaims 认证通过后保存在 gin context 中的身份信息
+type AuthClaims struct {
+	Subject     string
+	Username    string
+	Roles       []string
+	Permissions []string
+	// Raw 原始 claims, 自定义字段从这里读取
+	Raw jwt.MapClaims
+}
+
+// HasRole 是否拥有任一角色
+func (a *AuthClaims) HasRole(roles ...string) bool {
+	for _, r := range roles {
+		for _, have := range a.Roles {
+			if have == r {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasPermissions 是否拥有全部权限, 与 API Key scope 的规则一致, "*" 表示全部
+func (a *AuthClaims) HasPermissions(perms ...string) bool {
+	return exapikey.MatchScopes(a.Permissions, perms...)
+}
+
+// JWTAuthOptions JWTAuth 中间件配置
+type JWTAuthOptions struct {
+	// Secret HS256 密钥, 为空时使用环境变量 JWT_SECRET
+	Secret []byte
+	// KeySet 非对称密钥集合(RS256/ES256/EdDSA), 按 kid 选择验证公钥, 设置后忽略 Secret
+	KeySet *exjwt.KeySet
+	// Parser 自定义解析函数, 设置后忽略 Secret
+	Parser func(token string) (jwt.MapClaims, error)
+	// TokenLookup 取 token 的位置, 按顺序查找, 默认 "header:Authorization"
+	// 例如 "header:Authorization,cookie:token,query:token"
+	TokenLookup string
+	// IdentityFunc 将 claims 转换为 AuthClaims, 默认读取 sub/username/roles/permissions
+	IdentityFunc func(claims jwt.MapClaims) *AuthClaims
+	// Optional 为 true 时缺少 token 也放行, 仅在 token 存在时校验
+	Optional bool
+}
+
+// JWTAuth 提取并校验 Bearer token, 通过后将身份信息写入 context, 失败返回 401
+func JWTAuth(opts *JWTAuthOptions) gin.HandlerFunc {
+	if opts == nil {
+		opts = &JWTAuthOptions{}
+	}
+	if opts.TokenLookup == "" {
+		opts.TokenLookup = "header:Authorization"
+	}
+	if opts.Parser == nil {
+		secret, ks := opts.Secret, opts.KeySet
+		opts.Parser = func(token string) (jwt.MapClaims, error) {
+			if ks != nil {
+				return exjwt.ParseWithKeySet(token, ks)
+			}
+			if len(secret) == 0 {
+				return exjwt.ParseMap(token)
+			}
+			return exjwt.ParseMapWithSecret(token, secret)
+		}
+	}
+	if opts.IdentityFunc == nil {
+		opts.IdentityFunc = defaultIdentity
+	}
+	lookups := strings.Split(opts.TokenLookup, ",")
+
+	return func(c *gin.Context) {
+		token := extractToken(c, lookups)
+		if token == "" {
+			if opts.Optional {
+				c.Next()
+				return
+			}
+			GinsAbort(c, http.StatusUnauthorized, "缺少认证信息")
+			return
+		}
+		claims, err := opts.Parser(token)
+		if err != nil {
+			// 解析细节(签名、kid、自定义 Parser 的错误)只记录在服务端, 不返回给未认证的客户端
+			exctx.Logger(c.Request.Context()).WithField("Tag", "exgin").Warnf("jwt auth failed: %v", err)
+			if errors.Is(err, exjwt.ErrExpired) {
+				GinsAbort(c, http.StatusUnauthorized, "认证已过期")
+				return
+			}
+			GinsAbort(c, http.StatusUnauthorized, "认证失败")
+			return
+		}
+		setAuthClaims(c, opts.IdentityFunc(claims))
+		c.Next()
+	}
+}
+
+// JWKSHandler 发布密钥集合的公钥(JWKS), 供其他服务验证 token, 不包含私钥
+// 直接输出标准 JWKS 文档而不是统一响应结构, 通常挂载在 /.well-known/jwks.json
+func JWKSHandler(ks *exjwt.KeySet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		doc, err := ks.JWKS()
+		if err != nil {
+			GinsAbort(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+func extractToken(c *gin.Context, lookups []string) string {
+	for _, lookup := range lookups {
+		source, name, ok := strings.Cut(strings.TrimSpace(lookup), ":")
+		if !ok {
+			continue
+		}
+		var token string
+		switch source {
+		case "header":
+			token = c.GetHeader(name)
+			if len(token) > 7 && strings.EqualFold(token[:7], "Bearer ") {
+				token = token[7:]
+			}
+		case "cookie":
+			token, _ = c.Cookie(name)
+		case "query":
+			token = c.Query(name)
+		}
+		if token = strings.TrimSpace(token); token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+func defaultIdentity(claims jwt.MapClaims) *AuthClaims {
+	a := &AuthClaims{Raw: claims}
+	a.Subject, _ = claims["sub"].(string)
+	a.Username, _ = claims["username"].(string)
+	a.Roles = claimStrings(claims, "roles", "role")
+	a.Permissions = claimStrings(claims, "permissions", "scope")
+	return a
+}
+
+// claimStrings 读取字符串数组或空格分隔的字符串 claim
+func claimStrings(claims jwt.MapClaims, keys ...string) []string {
+	var out []string
+	for _, key := range keys {
+		switch v := claims[key].(type) {
+		case string:
+			out = append(out, strings.Fields(v)...)
+		case []string:
+			out = append(out, v...)
+		case []any:
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					out = append(out, s)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// setAuthClaims 保存身份信息, 并将用户写入 request context 供 exctx.Logger 输出
+func setAuthClaims(c *gin.Context, claims *AuthClaims) {
+	c.Set(authClaimsKey, claims)
+	if claims == nil {
+		return
+	}
+	user := claims.Subject
+	if user == "" {
+		user = claims.Username
+	}
+	if user != "" {
+		c.Request = c.Request.WithContext(exctx.WithUser(c.Request.Context(), user))
+	}
+}
+
+// GetAuthClaims 获取 JWTAuth 写入的身份信息
+func GetAuthClaims(c *gin.Context) (*AuthClaims, bool) {
+	v, exists := c.Get(authClaimsKey)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := v.(*AuthClaims)
+	return claims, ok
+}
+
+// Policy 自定义鉴权策略, 返回 true 表示允许访问
+type Policy func(c *gin.Context, claims *AuthClaims) bool
+
+// RequirePolicy 按策略鉴权, 未认证返回 401, 无权限返回 403
+func RequirePolicy(policy Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := GetAuthClaims(c)
+		if !ok {
+			GinsAbort(c, http.StatusUnauthorized, "缺少认证信息")
+			return
+		}
+		if !policy(c, claims) {
+			GinsAbort(c, http.StatusForbidden, "无权访问")
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRoles 拥有任一角色即可访问
+func RequireRoles(roles ...string) gin.HandlerFunc {
+	return RequirePolicy(func(_ *gin.Context, claims *AuthClaims) bool {
+		return claims.HasRole(roles...)
+	})
+}
+
+// RequirePermissions 需同时拥有全部权限
+func RequirePermissions(perms ...string) gin.HandlerFunc {
+	return RequirePolicy(func(_ *gin.Context, claims *AuthClaims) bool {
+		return claims.HasPermissions(perms...)
+	})
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	errors "github.com/ergoapi/util/exerror"
+	"github.com/ergoapi/util/exjwt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secret := []byte("test-secret")
+	token, err := exjwt.AuthWithSecret("alice", "uuid-1", secret)
+	require.NoError(t, err)
+
+	r := gin.New()
+	api := r.Group("/api", JWTAuth(&JWTAuthOptions{
+		Secret:      secret,
+		TokenLookup: "header:Authorization,cookie:token,query:token",
+	}))
+	api.GET("/me", func(c *gin.Context) {
+		claims, ok := GetAuthClaims(c)
+		require.True(t, ok)
+		SucessResponse(c, claims.Username+"/"+claims.Subject)
+	})
+
+	tests := []struct {
+		name  string
+		setup func(req *http.Request)
+		code  int
+	}{
+		{"missing", func(*http.Request) {}, http.StatusUnauthorized},
+		{"invalid", func(req *http.Request) { req.Header.Set("Authorization", "Bearer bad") }, http.StatusUnauthorized},
+		{"header", func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+token) }, http.StatusOK},
+		{"cookie", func(req *http.Request) { req.AddCookie(&http.Cookie{Name: "token", Value: token}) }, http.StatusOK},
+		{"query", func(req *http.Request) { req.URL.RawQuery = "token=" + token }, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/me", nil)
+			tt.setup(req)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.code, w.Code)
+			if tt.code == http.StatusOK {
+				assert.Contains(t, w.Body.String(), "alice/uuid-1")
+			}
+		})
+	}
+}
+
+func TestRequireRoles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	parser := func(token string) (jwt.MapClaims, error) {
+		return jwt.MapClaims{"sub": token, "roles": []any{token}, "scope": "read write"}, nil
+	}
+	r := gin.New()
+	admin := r.Group("/admin", JWTAuth(&JWTAuthOptions{Parser: parser}), RequireRoles("admin"))
+	admin.GET("/", func(c *gin.Context) { SucessResponse(c, "ok") })
+	write := r.Group("/write", JWTAuth(&JWTAuthOptions{Parser: parser}), RequirePermissions("read", "write"))
+	write.GET("/", func(c *gin.Context) { SucessResponse(c, "ok") })
+
+	do := func(path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusOK, do("/admin/", "admin"))
+	assert.Equal(t, http.StatusForbidden, do("/admin/", "user"))
+	assert.Equal(t, http.StatusOK, do("/write/", "user"))
+}
+
+func TestJWTAuthHidesParserErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secret := []byte("test-secret")
+	r := gin.New()
+	r.GET("/custom", JWTAuth(&JWTAuthOptions{
+		Parser: func(string) (jwt.MapClaims, error) {
+			return nil, fmt.Errorf("kid lookup: dial tcp 10.0.0.5:5432")
+		},
+	}), func(c *gin.Context) { SucessResponse(c, "ok") })
+	r.GET("/default", JWTAuth(&JWTAuthOptions{Secret: secret}), func(c *gin.Context) { SucessResponse(c, "ok") })
+
+	do := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	w := do("/custom", "t")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "认证失败")
+	assert.NotContains(t, w.Body.String(), "10.0.0.5")
+
+	wrong, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "u1"}).SignedString([]byte("other"))
+	require.NoError(t, err)
+	w = do("/default", wrong)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.NotContains(t, w.Body.String(), "signature")
+
+	expired, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "u1", "exp": time.Now().Add(-time.Hour).Unix()}).SignedString(secret)
+	require.NoError(t, err)
+	w = do("/default", expired)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "认证已过期")
+}
+
+func TestRequireRolesWithSignedToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secret := []byte("test-secret")
+	sign := func(claims jwt.MapClaims) string {
+		if _, ok := claims["exp"]; !ok {
+			claims["exp"] = time.Now().Add(time.Hour).Unix()
+		}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		require.NoError(t, err)
+		return token
+	}
+	r := gin.New()
+	auth := JWTAuth(&JWTAuthOptions{Secret: secret})
+	r.GET("/admin", auth, RequireRoles("admin"), func(c *gin.Context) { SucessResponse(c, "ok") })
+	r.GET("/write", auth, RequirePermissions("write"), func(c *gin.Context) { SucessResponse(c, "ok") })
+
+	do := func(path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	admin := sign(jwt.MapClaims{"sub": "u1", "roles": []string{"admin"}, "scope": "read write"})
+	user := sign(jwt.MapClaims{"sub": "u2", "role": "member"})
+	assert.Equal(t, http.StatusOK, do("/admin", admin))
+	assert.Equal(t, http.StatusOK, do("/write", admin))
+	assert.Equal(t, http.StatusForbidden, do("/admin", user))
+	assert.Equal(t, http.StatusForbidden, do("/write", user))
+	assert.Equal(t, http.StatusUnauthorized, do("/admin", sign(jwt.MapClaims{"sub": "u1", "roles": "admin", "exp": time.Now().Add(-time.Hour).Unix()})))
+}
+
+func TestRecoveryErrorCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ExRecovery())
+	r.GET("/", func(*gin.Context) { errors.BombCode(http.StatusUnauthorized, "token expired") })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestJWKSHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := exjwt.NewKey("2025-06", priv)
+	require.NoError(t, err)
+	ks, err := exjwt.NewKeySet(key)
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.GET("/.well-known/jwks.json", JWKSHandler(ks))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// 只持有公钥的服务通过 JWKS 验证 token
+	verifier, err := exjwt.ParseJWKS(w.Body.Bytes())
+	require.NoError(t, err)
+	api := r.Group("/api", JWTAuth(&JWTAuthOptions{KeySet: verifier}))
+	api.GET("/me", func(c *gin.Context) {
+		claims, _ := GetAuthClaims(c)
+		SucessResponse(c, claims.Username)
+	})
+	token, err := exjwt.AuthWithKeySet("alice", "uuid-1", ks)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/api/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "alice")
+
+	hs, _ := exjwt.AuthWithSecret("alice", "uuid-1", []byte("secret"))
+	req.Header.Set("Authorization", "Bearer "+hs)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const redactedValue = "******"
+
+var (
+	defaultRedactFields     = []string{"password", "passwd", "token", "access_token", "refresh_token", "secret", "idcard"}
+	defaultRedactHeaders    = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+	defaultBodyContentTypes = []string{"application/json", "application/x-www-form-urlencoded", "text/"}
+)
+
+// LogOptions ExLogWithOptions 配置
+type LogOptions struct {
+	// Skip 不记录日志的路径前缀
+	Skip []string
+	// LogBody 开启请求体和响应体记录
+	LogBody bool
+	// LogHeaders 记录请求头, 敏感头会被脱敏
+	LogHeaders bool
+	// MaxBodySize 记录的 body 最大字节数, 默认 4KB, 超出部分截断
+	MaxBodySize int
+	// BodyContentTypes 允许记录 body 的 Content-Type 前缀, 默认 json/form/text
+	BodyContentTypes []string
+	// BodyPaths 仅记录这些路径前缀的 body, 为空时记录全部
+	BodyPaths []string
+	// RedactFields 需要脱敏的 JSON/表单字段, 不区分大小写, 默认 password/token/idcard 等
+	RedactFields []string
+	// RedactHeaders 需要脱敏的请求头, 默认 Authorization/Cookie 等
+	RedactHeaders []string
+	// SlowThreshold 超过该耗时额外输出慢请求警告, 默认 3s, 小于 0 表示关闭
+	// 已安装 SlowRequest 中间件(如 Config.Slow)时由 SlowRequest 负责告警, 此处不再输出
+	SlowThreshold time.Duration
+}
+
+func (o *LogOptions) setDefaults() {
+	if o.SlowThreshold == 0 {
+		o.SlowThreshold = defaultGinSlowThreshold
+	}
+	if o.MaxBodySize <= 0 {
+		o.MaxBodySize = 4 << 10
+	}
+	if len(o.BodyContentTypes) == 0 {
+		o.BodyContentTypes = defaultBodyContentTypes
+	}
+	if len(o.RedactFields) == 0 {
+		o.RedactFields = defaultRedactFields
+	}
+	if len(o.RedactHeaders) == 0 {
+		o.RedactHeaders = defaultRedactHeaders
+	}
+}
+
+// bodyRedactor 按字段名对 JSON 和表单 body 脱敏, 对截断后的 JSON 同样有效
+type bodyRedactor struct {
+	fields   map[string]struct{}
+	headers  map[string]struct{}
+	jsonRe   *regexp.Regexp
+	nestedRe *regexp.Regexp
+}
+
+func newBodyRedactor(fields, headers []string) *bodyRedactor {
+	r := &bodyRedactor{fields: map[string]struct{}{}, headers: map[string]struct{}{}}
+	quoted := make([]string, 0, len(fields))
+	for _, f := range fields {
+		r.fields[strings.ToLower(f)] = struct{}{}
+		quoted = append(quoted, regexp.QuoteMeta(f))
+	}
+	for _, h := range headers {
+		r.headers[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+	if len(quoted) > 0 {
+		// "field": "value" / "field": 123, value 可能因截断而不完整
+		r.jsonRe = regexp.MustCompile(`(?i)("(?:` + strings.Join(quoted, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,{}\[\]\s]+)`)
+		r.nestedRe = regexp.MustCompile(`(?i)"(?:` + strings.Join(quoted, "|") + `)"\s*:\s*[\[{]`)
+	}
+	return r
+}
+
+func (r *bodyRedactor) body(contentType string, body []byte) string {
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		if values, err := url.ParseQuery(string(body)); err == nil {
+			for k := range values {
+				if _, ok := r.fields[strings.ToLower(k)]; ok {
+					values.Set(k, redactedValue)
+				}
+			}
+			return values.Encode()
+		}
+	}
+	if r.jsonRe == nil {
+		return string(body)
+	}
+	if out, ok := r.jsonBody(body); ok {
+		return out
+	}
+	// 截断或不合法的 JSON 按正则替换标量值; 敏感字段的值为对象或数组时无法确定结束位置, 从该处截断
+	out := r.jsonRe.ReplaceAllString(string(body), `${1}"`+redactedValue+`"`)
+	if loc := r.nestedRe.FindStringIndex(out); loc != nil {
+		out = out[:loc[1]-1] + `"` + redactedValue + `"`
+	}
+	return out
+}
+
+// jsonBody 逐 token 重写完整的 JSON 并递归脱敏, 保留字段顺序, 敏感字段的值无论是标量、对象还是数组都整体替换
+func (r *bodyRedactor) jsonBody(body []byte) (string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var buf bytes.Buffer
+	if err := r.redactJSON(dec, &buf); err != nil {
+		return "", false
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+func (r *bodyRedactor) redactJSON(dec *json.Decoder, buf *bytes.Buffer) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return writeJSONValue(buf, tok)
+	}
+	open, isObject := byte(delim), delim == '{'
+	buf.WriteByte(open)
+	for i := 0; dec.More(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if isObject {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if err := writeJSONValue(buf, key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if _, ok := r.fields[strings.ToLower(key.(string))]; ok {
+				var skip json.RawMessage
+				if err := dec.Decode(&skip); err != nil {
+					return err
+				}
+				buf.WriteString(`"` + redactedValue + `"`)
+				continue
+			}
+		}
+		if err := r.redactJSON(dec, buf); err != nil {
+			return err
+		}
+	}
+	// 读取配对的结束符
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	if isObject {
+		buf.WriteByte('}')
+	} else {
+		buf.WriteByte(']')
+	}
+	return nil
+}
+
+func writeJSONValue(buf *bytes.Buffer, v any) error {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	// Encode 会追加换行
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
+
+func (r *bodyRedactor) header(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, vs := range h {
+		if _, ok := r.headers[http.CanonicalHeaderKey(k)]; ok {
+			out[k] = redactedValue
+			continue
+		}
+		out[k] = strings.Join(vs, ",")
+	}
+	return out
+}
+
+func matchContentType(contentType string, allowed []string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, a := range allowed {
+		if strings.HasPrefix(contentType, a) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPathPrefix(path string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// peekRequestBody 读取至多 limit 字节的请求体用于记录, 并还原 Request.Body 供后续处理
+func peekRequestBody(c *gin.Context, limit int) ([]byte, bool) {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil, false
+	}
+	buf, _ := io.ReadAll(io.LimitReader(c.Request.Body, int64(limit)+1))
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), c.Request.Body), c.Request.Body}
+	if len(buf) > limit {
+		return buf[:limit], true
+	}
+	return buf, false
+}
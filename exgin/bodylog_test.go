@@ -0,0 +1,203 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ergoapi/util/exctx"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyRedactor(t *testing.T) {
+	r := newBodyRedactor(defaultRedactFields, defaultRedactHeaders)
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{
+			name:        "nested json",
+			contentType: "application/json",
+			body:        `{"user":"bob","Password":"p@\"ss","profile":{"idcard":110101,"token":"abc"}}`,
+			want:        `{"user":"bob","Password":"******","profile":{"idcard":"******","token":"******"}}`,
+		},
+		{
+			name:        "truncated json",
+			contentType: "application/json",
+			body:        `{"user":"bob","password":"secr`,
+			want:        `{"user":"bob","password":"******"`,
+		},
+		{
+			name:        "object and array values",
+			contentType: "application/json",
+			body:        `{"secret":{"key":"k1","nested":{"a":1}},"token":["t1","t2"],"items":[{"password":"p"}],"amount":12345678901234567890}`,
+			want:        `{"secret":"******","token":"******","items":[{"password":"******"}],"amount":12345678901234567890}`,
+		},
+		{
+			name:        "truncated json with object value",
+			contentType: "application/json",
+			body:        `{"user":"bob","secret":{"key":"k1","more":"x`,
+			want:        `{"user":"bob","secret":"******"`,
+		},
+		{
+			name:        "form",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "password=123&user=bob",
+			want:        "password=%2A%2A%2A%2A%2A%2A&user=bob",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, r.body(tt.contentType, []byte(tt.body)))
+		})
+	}
+
+	h := r.header(http.Header{"Authorization": {"Bearer x"}, "Accept": {"*/*"}})
+	assert.Equal(t, redactedValue, h["Authorization"])
+	assert.Equal(t, "*/*", h["Accept"])
+}
+
+func TestExLogWithBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hook := test.NewGlobal()
+	defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+
+	r := gin.New()
+	r.Use(ExLogWithOptions(&LogOptions{LogBody: true, LogHeaders: true, MaxBodySize: 64, BodyPaths: []string{"/api"}}))
+	r.POST("/api/login", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusOK, "application/json", body)
+	})
+
+	payload := `{"user":"bob","password":"hunter2","note":"` + strings.Repeat("x", 100) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/login", strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer abc")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, payload, w.Body.String(), "handler must still see the full body")
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	reqBody, _ := entry.Data["req_body"].(string)
+	assert.Contains(t, reqBody, `"password":"******"`)
+	assert.NotContains(t, reqBody, "hunter2")
+	assert.True(t, strings.HasSuffix(reqBody, "...(truncated)"))
+	assert.Contains(t, entry.Data["resp_body"], `"password":"******"`)
+	assert.Equal(t, redactedValue, entry.Data["req_headers"].(map[string]string)["Authorization"])
+}
+
+func TestExLogSpan(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	exp := exctx.NewMemoryExporter()
+	exctx.SetExporters(exp)
+	defer exctx.SetExporters()
+
+	r := gin.New()
+	r.Use(exTraceID(), ExLog())
+	var inner exctx.SpanData
+	r.GET("/users/:id", func(c *gin.Context) {
+		_, span := exctx.StartSpan(c.Request.Context(), "load user")
+		span.End()
+		inner = exp.Spans()[0]
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exp.Spans()
+	require.Len(t, spans, 2)
+	server := spans[1]
+	assert.Equal(t, "GET /users/:id", server.Name)
+	assert.Equal(t, exctx.SpanKindServer, server.Kind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", server.ParentSpanID)
+	assert.Equal(t, 500, server.Attributes["http.response.status_code"])
+	assert.Equal(t, exctx.StatusError, server.StatusCode)
+	assert.Equal(t, server.SpanID, inner.ParentSpanID)
+
+	// 无 traceparent 时沿用 X-Trace-Id
+	exp.Reset()
+	req = httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("X-Trace-Id", "0f8fad5b-d9cb-469f-a165-70867728950e")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "0f8fad5bd9cb469fa16570867728950e", exp.Spans()[1].TraceID)
+}
+
+func TestExLogContextFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hook := test.NewGlobal()
+	defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+
+	r := gin.New()
+	r.Use(exTraceID(), ExLog())
+	r.GET("/me", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(exctx.WithTenant(exctx.WithUser(c.Request.Context(), "alice"), "acme"))
+		exctx.Logger(c.Request.Context()).Info("handler")
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("X-Trace-Id", "0f8fad5b-d9cb-469f-a165-70867728950e")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := hook.AllEntries()
+	require.Len(t, entries, 2)
+	handler, access := entries[0], entries[1]
+	assert.Equal(t, "0f8fad5bd9cb469fa16570867728950e", handler.Data["traceID"])
+	assert.Equal(t, handler.Data["SpanID"], access.Data["SpanID"])
+	assert.Equal(t, handler.Data["traceID"], access.Data["traceID"])
+	assert.Equal(t, "alice", access.Data["user"])
+	assert.Equal(t, "acme", access.Data["tenant"])
+	assert.Equal(t, "exgin", access.Data["Tag"])
+}
+
+func TestExLogSlowWarningWithSlowRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hook := test.NewGlobal()
+	defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+
+	warnings := func(mws ...gin.HandlerFunc) int {
+		hook.Reset()
+		r := gin.New()
+		r.Use(mws...)
+		r.GET("/", func(c *gin.Context) {
+			time.Sleep(5 * time.Millisecond)
+			c.Status(http.StatusOK)
+		})
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		n := 0
+		for _, e := range hook.AllEntries() {
+			if e.Level == logrus.WarnLevel {
+				n++
+			}
+		}
+		return n
+	}
+	opts := &LogOptions{SlowThreshold: time.Millisecond}
+	assert.Equal(t, 1, warnings(ExLogWithOptions(opts)))
+	// 同时安装 SlowRequest 时只由 SlowRequest 告警
+	slow := &SlowRequestOptions{Threshold: time.Millisecond}
+	assert.Equal(t, 1, warnings(ExLogWithOptions(opts), SlowRequest(slow)))
+	assert.Equal(t, 1, warnings(SlowRequest(slow), ExLogWithOptions(opts)))
+	// 默认值不写回调用方的配置
+	assert.Zero(t, slow.SnapshotInterval)
+	assert.Zero(t, opts.MaxBodySize)
+}
@@ -0,0 +1,331 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+	encodingZstd    = "zstd"
+)
+
+var (
+	defaultCompressEncodings    = []string{encodingZstd, encodingGzip, encodingDeflate}
+	defaultCompressContentTypes = []string{
+		"application/json", "application/problem+json", "application/x-ndjson",
+		"application/javascript", "application/xml", "application/yaml",
+		"image/svg+xml", "text/",
+	}
+	defaultCompressExcludeTypes = []string{"text/event-stream"}
+)
+
+// CompressOptions Compress 配置
+type CompressOptions struct {
+	// MinSize 小于该字节数的响应不压缩, 默认 1KB
+	MinSize int
+	// Encodings 支持的编码及优先级, 默认 zstd、gzip、deflate
+	Encodings []string
+	// ContentTypes 允许压缩的 Content-Type 前缀, 默认 json/xml/javascript/text 等文本类型
+	ContentTypes []string
+	// ExcludeContentTypes 禁止压缩的 Content-Type 前缀, 默认 text/event-stream
+	ExcludeContentTypes []string
+	// ExcludePaths 不压缩的路径前缀
+	ExcludePaths []string
+}
+
+func (o *CompressOptions) setDefaults() {
+	if o.MinSize <= 0 {
+		o.MinSize = 1 << 10
+	}
+	if len(o.Encodings) == 0 {
+		o.Encodings = defaultCompressEncodings
+	}
+	if len(o.ContentTypes) == 0 {
+		o.ContentTypes = defaultCompressContentTypes
+	}
+	if len(o.ExcludeContentTypes) == 0 {
+		o.ExcludeContentTypes = defaultCompressExcludeTypes
+	}
+}
+
+var encoderPools = map[string]*sync.Pool{
+	encodingGzip: {New: func() any { return gzip.NewWriter(io.Discard) }},
+	encodingDeflate: {New: func() any {
+		return zlib.NewWriter(io.Discard)
+	}},
+	encodingZstd: {New: func() any {
+		w, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderLevel(zstd.SpeedDefault), zstd.WithEncoderConcurrency(1))
+		return w
+	}},
+}
+
+type resetWriteCloser interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+	Flush() error
+}
+
+// Compress 按 Accept-Encoding 协商压缩响应, 已压缩(设置了 Content-Encoding)、SSE 和小响应不压缩
+func Compress(opts *CompressOptions) gin.HandlerFunc {
+	if opts == nil {
+		opts = &CompressOptions{}
+	}
+	opts.setDefaults()
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || matchPathPrefix(c.Request.URL.Path, opts.ExcludePaths) {
+			c.Next()
+			return
+		}
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"), opts.Encodings)
+		if encoding == "" {
+			c.Next()
+			return
+		}
+		w := &compressWriter{ResponseWriter: c.Writer, opts: opts, encoding: encoding, status: http.StatusOK}
+		c.Writer = w
+		defer func() {
+			w.finish()
+			c.Writer = w.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// negotiateEncoding 选出客户端可接受(q>0)且权重最高的编码, 权重相同时按 supported 顺序
+func negotiateEncoding(header string, supported []string) string {
+	if header == "" {
+		return ""
+	}
+	weights := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		weights[name] = q
+	}
+	best, bestQ := "", 0.0
+	for _, enc := range supported {
+		q, ok := weights[enc]
+		if !ok {
+			q, ok = weights["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best
+}
+
+// compressWriter 缓冲响应直到达到 MinSize 再决定是否压缩
+type compressWriter struct {
+	gin.ResponseWriter
+	opts     *CompressOptions
+	encoding string
+
+	status   int
+	written  bool
+	decided  bool
+	size     int
+	buf      []byte
+	encoder  resetWriteCloser
+	finished bool
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if code > 0 {
+		w.status = code
+		w.written = true
+	}
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	if !w.decided {
+		w.decide(false)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *compressWriter) Status() int {
+	if w.decided {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *compressWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.size
+}
+
+func (w *compressWriter) Written() bool {
+	return w.written || w.decided && w.ResponseWriter.Written()
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	w.written = true
+	w.size += len(data)
+	if !w.decided {
+		w.buf = append(w.buf, data...)
+		if len(w.buf) < w.opts.MinSize {
+			return len(data), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		// 流式响应不等待 MinSize
+		_ = w.decide(false)
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if !w.decided {
+		w.decided = true
+	}
+	return w.ResponseWriter.Hijack()
+}
+
+// decide 决定是否压缩, 写出响应头和已缓冲的数据
+func (w *compressWriter) decide(enoughData bool) error {
+	w.decided = true
+	h := w.Header()
+	contentType := h.Get("Content-Type")
+	if contentType == "" && len(w.buf) > 0 {
+		contentType = http.DetectContentType(w.buf)
+	}
+	eligible := h.Get("Content-Encoding") == "" &&
+		w.status != http.StatusNoContent && w.status != http.StatusNotModified && w.status >= http.StatusOK &&
+		matchContentType(contentType, w.opts.ContentTypes) &&
+		!matchContentType(contentType, w.opts.ExcludeContentTypes)
+	if eligible {
+		// 响应内容取决于 Accept-Encoding, 即使本次未压缩也需要告知缓存
+		h.Add("Vary", "Accept-Encoding")
+	}
+	if eligible && enoughData {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		w.encoder = encoderPools[w.encoding].Get().(resetWriteCloser)
+		w.encoder.Reset(w.ResponseWriter)
+	}
+	if w.written {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.encoder != nil {
+		_, err = w.encoder.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+func (w *compressWriter) finish() {
+	if w.finished {
+		return
+	}
+	w.finished = true
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+		w.encoder.Reset(io.Discard)
+		encoderPools[w.encoding].Put(w.encoder)
+		w.encoder = nil
+	}
+}
+
+// Decompress 透明解压 Content-Encoding 为 gzip/deflate/zstd 的请求体
+// maxSize 为解压后的大小上限, 防止压缩炸弹, 超出时 Bind 返回 413; 不支持的编码返回 415
+func Decompress(maxSize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+		if encoding == "" || encoding == "identity" || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		var (
+			reader io.ReadCloser
+			err    error
+		)
+		switch encoding {
+		case encodingGzip, "x-gzip":
+			reader, err = gzip.NewReader(c.Request.Body)
+		case encodingDeflate:
+			reader, err = zlib.NewReader(c.Request.Body)
+		case encodingZstd:
+			var dec *zstd.Decoder
+			dec, err = zstd.NewReader(c.Request.Body, zstd.WithDecoderConcurrency(1))
+			if err == nil {
+				reader = dec.IOReadCloser()
+			}
+		default:
+			GinsAbort(c, http.StatusUnsupportedMediaType, "不支持的 Content-Encoding: "+encoding)
+			return
+		}
+		if err != nil {
+			GinsAbort(c, http.StatusBadRequest, "请求体解压失败")
+			return
+		}
+		defer reader.Close()
+		if maxSize > 0 {
+			reader = http.MaxBytesReader(c.Writer, reader, maxSize)
+		}
+		c.Request.Body = reader
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Del("Content-Length")
+		c.Request.ContentLength = -1
+		c.Next()
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	supported := defaultCompressEncodings
+	assert.Equal(t, "zstd", negotiateEncoding("gzip, deflate, br, zstd", supported))
+	assert.Equal(t, "gzip", negotiateEncoding("zstd;q=0, gzip", supported))
+	assert.Equal(t, "deflate", negotiateEncoding("gzip;q=0.5, deflate", supported))
+	assert.Equal(t, "zstd", negotiateEncoding("*", supported))
+	assert.Empty(t, negotiateEncoding("br", supported))
+	assert.Empty(t, negotiateEncoding("", supported))
+}
+
+func TestCompress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	large := strings.Repeat(`{"name":"pod","status":"Running"},`, 200)
+	r := gin.New()
+	r.Use(Compress(nil))
+	r.GET("/large", func(c *gin.Context) { c.Data(http.StatusOK, "application/json", []byte(large)) })
+	r.GET("/small", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) })
+	r.GET("/png", func(c *gin.Context) { c.Data(http.StatusOK, "image/png", []byte(large)) })
+	r.GET("/gz", func(c *gin.Context) {
+		c.Header("Content-Encoding", "gzip")
+		c.Data(http.StatusOK, "application/json", []byte(large))
+	})
+	r.GET("/sse", func(c *gin.Context) {
+		s := NewSSEStream(c, 0)
+		_ = s.Send(Event{Data: large})
+	})
+
+	get := func(path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", accept)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, enc := range []string{"gzip", "deflate", "zstd"} {
+		w := get("/large", enc)
+		require.Equal(t, enc, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		assert.Empty(t, w.Header().Get("Content-Length"))
+		assert.Less(t, w.Body.Len(), len(large))
+		assert.Equal(t, large, decode(t, enc, w.Body.Bytes()))
+	}
+
+	w := get("/large", "")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, large, w.Body.String())
+
+	w = get("/small", "gzip")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"), "small responses still vary")
+	assert.JSONEq(t, `{"ok":true}`, w.Body.String())
+
+	w = get("/png", "gzip")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, large, w.Body.String())
+
+	w = get("/gz", "zstd")
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"), "already compressed payload untouched")
+	assert.Equal(t, large, w.Body.String())
+
+	w = get("/sse", "gzip")
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Contains(t, w.Body.String(), "data:"+large)
+}
+
+func decode(t *testing.T, encoding string, data []byte) string {
+	t.Helper()
+	var (
+		r   io.Reader
+		err error
+	)
+	switch encoding {
+	case "gzip":
+		r, err = gzip.NewReader(bytes.NewReader(data))
+	case "deflate":
+		r, err = zlib.NewReader(bytes.NewReader(data))
+	case "zstd":
+		var dec *zstd.Decoder
+		dec, err = zstd.NewReader(bytes.NewReader(data))
+		if err == nil {
+			defer dec.Close()
+			r = dec
+		}
+	}
+	require.NoError(t, err)
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestDecompress(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ExRecovery(), Decompress(64))
+	r.POST("/bind", func(c *gin.Context) {
+		var v map[string]string
+		Bind(c, &v)
+		SucessResponse(c, v["name"])
+	})
+
+	post := func(encoding string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/bind", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", encoding)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	payload := []byte(`{"name":"demo"}`)
+	var gz, zl bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	_, _ = gw.Write(payload)
+	require.NoError(t, gw.Close())
+	zw := zlib.NewWriter(&zl)
+	_, _ = zw.Write(payload)
+	require.NoError(t, zw.Close())
+	enc, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	zs := enc.EncodeAll(payload, nil)
+
+	for encoding, body := range map[string][]byte{"gzip": gz.Bytes(), "deflate": zl.Bytes(), "zstd": zs} {
+		w := post(encoding, body)
+		assert.Equal(t, http.StatusOK, w.Code, encoding)
+		assert.Contains(t, w.Body.String(), `"data":"demo"`, encoding)
+	}
+
+	bomb := enc.EncodeAll([]byte(`{"name":"`+strings.Repeat("a", 1<<12)+`"}`), nil)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post("zstd", bomb).Code)
+	assert.Equal(t, http.StatusUnsupportedMediaType, post("br", payload).Code)
+	assert.Equal(t, http.StatusBadRequest, post("gzip", payload).Code)
+}
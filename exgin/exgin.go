@@ -56,7 +56,21 @@ type Config struct {
 	NoTrace        bool
 	Metrics        bool
 	MetricsPath    string
+	Health         bool
+	HealthPath     string
 	TrustedProxies []string
+	// RequestTimeout 全局请求超时, 0 表示不限制
+	// 路由上再加 Timeout 中间件只能缩短超时, 不能延长(子 context 保留更早的截止时间),
+	// 需要更长超时的路由应不设置全局超时, 改为按路由组使用 Timeout
+	RequestTimeout time.Duration
+	// MaxBodySize 请求体大小上限(字节), 0 表示不限制
+	MaxBodySize int64
+	// Slow 不为 nil 时启用慢请求记录(SlowRequest), 可配置 goroutine 快照
+	Slow *SlowRequestOptions
+	// Secure 不为 nil 时启用安全响应头
+	Secure *SecureOptions
+	// BotRules 不为空时启用爬虫/扫描器拦截, 可使用 DefaultBotRules
+	BotRules []BotRule
 }
 
 func (c *Config) GinSet(r *gin.Engine) {
@@ -78,6 +92,21 @@ func (c *Config) GinSet(r *gin.Engine) {
 	if !c.NoTrace {
 		r.Use(exTraceID())
 	}
+	if len(c.BotRules) > 0 {
+		r.Use(BotBlock(c.BotRules...))
+	}
+	if c.Secure != nil {
+		r.Use(Secure(c.Secure))
+	}
+	if c.Slow != nil {
+		r.Use(SlowRequest(c.Slow))
+	}
+	if c.MaxBodySize > 0 {
+		r.Use(BodyLimit(c.MaxBodySize))
+	}
+	if c.RequestTimeout > 0 {
+		r.Use(Timeout(c.RequestTimeout))
+	}
 	if c.Gops {
 		if c.GopsPath == "" {
 			c.GopsPath = "0.0.0.0:32388"
@@ -93,11 +122,34 @@ func (c *Config) GinSet(r *gin.Engine) {
 		pprof.Register(r, c.PprofPath)
 	}
 	if c.Metrics {
-		if c.MetricsPath == "" {
-			c.MetricsPath = "/metrics"
-		}
-		r.GET(c.MetricsPath, gin.WrapH(promhttp.Handler()))
+		registerMetrics(r, c.MetricsPath)
 	}
+	if c.Health {
+		// HealthPath 为探针路由前缀, 默认注册 /healthz /readyz /livez
+		DefaultHealth.RegisterRoutes(r, c.HealthPath)
+	}
+}
+
+func registerMetrics(r *gin.Engine, path string) {
+	if path == "" {
+		path = "/metrics"
+	}
+	r.GET(path, gin.WrapH(promhttp.Handler()))
+}
+
+// NewAdminEngine 管理端口使用的 engine, 注册 metrics、pprof(/debug/pprof) 和健康检查
+// 与 exhttp.Runner 配合, 将这些路由从业务端口中分离
+func NewAdminEngine(c *Config) *gin.Engine {
+	r := gin.New()
+	r.Use(gin.Recovery())
+	registerMetrics(r, c.MetricsPath)
+	pprofPath := c.PprofPath
+	if pprofPath == "" {
+		pprofPath = pprof.DefaultPrefix
+	}
+	pprof.Register(r, pprofPath)
+	DefaultHealth.RegisterRoutes(r, c.HealthPath)
+	return r
 }
 
 // Init init gin engine
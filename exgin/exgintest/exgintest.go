@@ -0,0 +1,239 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+// Package exgintest 提供 exgin handler 和中间件测试用的请求构造器和响应断言
+//
+//	h := exgintest.New(t, nil).WithJWTSecret(secret)
+//	h.Engine.GET("/users/:id", handler)
+//	h.GET("/users/1").AuthAs("alice", "uid-1").Do().
+//		Status(http.StatusOK).Code(200).HasTraceID().DataJSONEq(`{"id":1}`)
+package exgintest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ergoapi/util/exgin"
+	"github.com/ergoapi/util/exjwt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Harness 持有测试用的 engine
+type Harness struct {
+	t         testing.TB
+	Engine    *gin.Engine
+	jwtSecret []byte
+}
+
+// New 使用 exgin.Init 创建 engine, cfg 为 nil 时关闭 CORS, 其余保持默认
+func New(t testing.TB, cfg *exgin.Config) *Harness {
+	t.Helper()
+	if cfg == nil {
+		cfg = &exgin.Config{NoCors: true}
+	}
+	return &Harness{t: t, Engine: exgin.Init(cfg)}
+}
+
+// WithJWTSecret 设置 AuthAs 签发 token 使用的密钥, 需与被测 exgin.JWTAuth 的 Secret 一致
+func (h *Harness) WithJWTSecret(secret []byte) *Harness {
+	h.jwtSecret = secret
+	return h
+}
+
+// Use 为 engine 添加中间件
+func (h *Harness) Use(middleware ...gin.HandlerFunc) *Harness {
+	h.Engine.Use(middleware...)
+	return h
+}
+
+// NewRequest 构造请求
+func (h *Harness) NewRequest(method, path string) *Request {
+	return &Request{h: h, method: method, path: path, header: http.Header{}, query: url.Values{}}
+}
+
+// GET 构造 GET 请求
+func (h *Harness) GET(path string) *Request { return h.NewRequest(http.MethodGet, path) }
+
+// POST 构造 POST 请求
+func (h *Harness) POST(path string) *Request { return h.NewRequest(http.MethodPost, path) }
+
+// PUT 构造 PUT 请求
+func (h *Harness) PUT(path string) *Request { return h.NewRequest(http.MethodPut, path) }
+
+// PATCH 构造 PATCH 请求
+func (h *Harness) PATCH(path string) *Request { return h.NewRequest(http.MethodPatch, path) }
+
+// DELETE 构造 DELETE 请求
+func (h *Harness) DELETE(path string) *Request { return h.NewRequest(http.MethodDelete, path) }
+
+// Request 请求构造器
+type Request struct {
+	h      *Harness
+	method string
+	path   string
+	header http.Header
+	query  url.Values
+	body   io.Reader
+}
+
+// Header 设置请求头
+func (r *Request) Header(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// Query 追加 query 参数
+func (r *Request) Query(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// JSON 将 v 编码为 JSON 请求体
+func (r *Request) JSON(v any) *Request {
+	r.h.t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(r.h.t, err)
+	r.body = bytes.NewReader(data)
+	r.header.Set("Content-Type", "application/json")
+	return r
+}
+
+// Body 设置原始请求体
+func (r *Request) Body(contentType string, body io.Reader) *Request {
+	r.body = body
+	r.header.Set("Content-Type", contentType)
+	return r
+}
+
+// Bearer 设置 Authorization: Bearer token
+func (r *Request) Bearer(token string) *Request {
+	return r.Header("Authorization", "Bearer "+token)
+}
+
+// AuthAs 通过 exjwt 签发 token 并设置为 Bearer token
+func (r *Request) AuthAs(username, uuid string) *Request {
+	r.h.t.Helper()
+	require.NotEmpty(r.h.t, r.h.jwtSecret, "exgintest: call WithJWTSecret before AuthAs")
+	token, err := exjwt.AuthWithSecret(username, uuid, r.h.jwtSecret)
+	require.NoError(r.h.t, err)
+	return r.Bearer(token)
+}
+
+// Do 发送请求
+func (r *Request) Do() *Response {
+	r.h.t.Helper()
+	target := r.path
+	if len(r.query) > 0 {
+		u, err := url.Parse(r.path)
+		require.NoError(r.h.t, err)
+		q := u.Query()
+		for k, vs := range r.query {
+			q[k] = append(q[k], vs...)
+		}
+		u.RawQuery = q.Encode()
+		target = u.String()
+	}
+	req := httptest.NewRequest(r.method, target, r.body)
+	for k, vs := range r.header {
+		req.Header[k] = vs
+	}
+	w := httptest.NewRecorder()
+	r.h.Engine.ServeHTTP(w, req)
+	return &Response{t: r.h.t, Recorder: w}
+}
+
+// Envelope exgin 统一响应结构
+type Envelope struct {
+	Code      int             `json:"code"`
+	Data      json.RawMessage `json:"data"`
+	Message   string          `json:"message"`
+	Timestamp int64           `json:"timestamp"`
+	TraceID   string          `json:"traceId"`
+}
+
+// Response 响应断言
+type Response struct {
+	t        testing.TB
+	Recorder *httptest.ResponseRecorder
+	envelope *Envelope
+}
+
+// Envelope 解析响应体为 exgin 统一响应结构
+func (r *Response) Envelope() *Envelope {
+	r.t.Helper()
+	if r.envelope == nil {
+		r.envelope = &Envelope{}
+		require.NoError(r.t, json.Unmarshal(r.Recorder.Body.Bytes(), r.envelope), "body: %s", r.Recorder.Body.String())
+	}
+	return r.envelope
+}
+
+// Status 断言 HTTP 状态码
+func (r *Response) Status(code int) *Response {
+	r.t.Helper()
+	assert.Equal(r.t, code, r.Recorder.Code, "body: %s", r.Recorder.Body.String())
+	return r
+}
+
+// Code 断言响应体中的 code
+func (r *Response) Code(code int) *Response {
+	r.t.Helper()
+	assert.Equal(r.t, code, r.Envelope().Code)
+	return r
+}
+
+// Message 断言响应体中的 message
+func (r *Response) Message(msg string) *Response {
+	r.t.Helper()
+	assert.Equal(r.t, msg, r.Envelope().Message)
+	return r
+}
+
+// HasTraceID 断言响应体带有 traceId 且与 X-Trace-Id 头一致
+func (r *Response) HasTraceID() *Response {
+	r.t.Helper()
+	traceID := r.Envelope().TraceID
+	assert.NotEmpty(r.t, traceID)
+	assert.Equal(r.t, r.Recorder.Header().Get("X-Trace-Id"), traceID)
+	return r
+}
+
+// Header 断言响应头
+func (r *Response) Header(key, value string) *Response {
+	r.t.Helper()
+	assert.Equal(r.t, value, r.Recorder.Header().Get(key))
+	return r
+}
+
+// DataJSONEq 断言 data 字段与 expected JSON 等价
+func (r *Response) DataJSONEq(expected string) *Response {
+	r.t.Helper()
+	assert.JSONEq(r.t, expected, string(r.Envelope().Data))
+	return r
+}
+
+// DecodeData 将 data 字段解析到 out
+func (r *Response) DecodeData(out any) *Response {
+	r.t.Helper()
+	require.NoError(r.t, json.Unmarshal(r.Envelope().Data, out))
+	return r
+}
+
+// ValidationErrors 解析参数校验失败时 data 中的字段错误列表
+func (r *Response) ValidationErrors() exgin.ValidationErrors {
+	r.t.Helper()
+	var verrs exgin.ValidationErrors
+	r.DecodeData(&verrs)
+	return verrs
+}
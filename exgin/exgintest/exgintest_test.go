@@ -0,0 +1,111 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgintest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/ergoapi/util/cache"
+	"github.com/ergoapi/util/exgin"
+	"github.com/ergoapi/util/exjwt"
+	"github.com/ergoapi/util/feat/ginmid/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type createUser struct {
+	Name string `json:"name" binding:"required"`
+}
+
+func TestHarness(t *testing.T) {
+	secret := []byte("test-secret")
+	h := New(t, nil).WithJWTSecret(secret).Use(exgin.ExRecovery())
+	api := h.Engine.Group("/api", exgin.JWTAuth(&exgin.JWTAuthOptions{Secret: secret}))
+	api.POST("/users", func(c *gin.Context) {
+		var req createUser
+		exgin.Bind(c, &req)
+		claims, _ := exgin.GetAuthClaims(c)
+		exgin.SucessResponse(c, gin.H{"name": req.Name, "by": claims.Username, "page": c.Query("page")})
+	})
+
+	var out map[string]string
+	h.POST("/api/users").AuthAs("alice", "uid-1").Query("page", "2").JSON(createUser{Name: "bob"}).Do().
+		Status(http.StatusOK).
+		Code(http.StatusOK).
+		HasTraceID().
+		DataJSONEq(`{"name":"bob","by":"alice","page":"2"}`).
+		DecodeData(&out)
+	assert.Equal(t, "bob", out["name"])
+
+	verrs := h.POST("/api/users").AuthAs("alice", "uid-1").JSON(gin.H{}).Do().
+		Status(http.StatusBadRequest).
+		ValidationErrors()
+	require.Len(t, verrs, 1)
+	assert.Equal(t, "name", verrs[0].Field)
+
+	h.POST("/api/users").JSON(createUser{Name: "bob"}).Do().Status(http.StatusUnauthorized).Code(http.StatusUnauthorized)
+}
+
+func TestFakeLimitStore(t *testing.T) {
+	store := NewFakeLimitStore(2)
+	h := New(t, nil).Use(ratelimit.RateLimiter(store, &ratelimit.Options{
+		KeyFunc: func(*gin.Context, ...string) string { return "k" },
+	}))
+	h.Engine.GET("/", func(c *gin.Context) { exgin.SucessResponse(c, nil) })
+
+	h.GET("/").Do().Status(http.StatusOK).Header("X-Rate-Limit-Remaining", "1")
+	h.GET("/").Do().Status(http.StatusOK).Header("X-Rate-Limit-Remaining", "0")
+	h.GET("/").Do().Status(http.StatusTooManyRequests)
+	assert.Equal(t, uint(3), store.Hits("k"))
+
+	store.Reset()
+	h.GET("/").Do().Status(http.StatusOK)
+}
+
+func TestFakeCache(t *testing.T) {
+	ctx := context.Background()
+	c := NewFakeCache()
+	require.NoError(t, c.Set(ctx, "a", 1))
+	require.NoError(t, c.Set(ctx, "b", 2, cache.WithExpiration(time.Minute)))
+
+	_, ttl, err := c.GetWithTTL(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, ttl)
+
+	c.Advance(2 * time.Minute)
+	_, err = c.Get(ctx, "b")
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+	assert.Equal(t, []string{"a"}, c.Keys())
+
+	c.Err = errors.New("down")
+	assert.Error(t, c.Ping(ctx))
+	_, err = c.Get(ctx, "a")
+	assert.ErrorIs(t, err, c.Err)
+}
+
+func TestFakeCacheWithTokenManager(t *testing.T) {
+	ctx := context.Background()
+	iss, err := exjwt.NewIssuer(exjwt.IssuerOptions[struct{}]{Secret: []byte("test-secret")})
+	require.NoError(t, err)
+	m := exjwt.NewTokenManager(iss, exjwt.NewCacheRefreshStore(NewFakeCache(), ""), exjwt.TokenManagerOptions[struct{}]{})
+
+	pair, err := m.Login(ctx, "alice", struct{}{})
+	require.NoError(t, err)
+	_, err = m.Parse(ctx, pair.AccessToken)
+	require.NoError(t, err)
+	next, err := m.Refresh(ctx, pair.RefreshToken)
+	require.NoError(t, err)
+
+	require.NoError(t, m.Logout(ctx, next.RefreshToken))
+	_, err = m.Parse(ctx, next.AccessToken)
+	assert.ErrorIs(t, err, exjwt.ErrTokenRevoked)
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgintest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ergoapi/util/cache"
+	"github.com/ergoapi/util/feat/ginmid/ratelimit"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeItem struct {
+	value    any
+	expireAt time.Time
+}
+
+// FakeCache 内存版 cache.Cache, 时间可手动推进, 可注入错误
+type FakeCache struct {
+	mu    sync.Mutex
+	now   time.Time
+	items map[string]fakeItem
+	// Err 不为 nil 时所有操作返回该错误
+	Err error
+}
+
+var _ cache.Cache = (*FakeCache)(nil)
+
+// NewFakeCache 创建 FakeCache
+func NewFakeCache() *FakeCache {
+	return &FakeCache{now: time.Now(), items: map[string]fakeItem{}}
+}
+
+// Advance 推进时间, 用于测试过期
+func (f *FakeCache) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+// Keys 返回未过期的 key, 按字典序
+func (f *FakeCache) Keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := make([]string, 0, len(f.items))
+	for k, it := range f.items {
+		if f.alive(it) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (f *FakeCache) alive(it fakeItem) bool {
+	return it.expireAt.IsZero() || f.now.Before(it.expireAt)
+}
+
+func (f *FakeCache) Get(ctx context.Context, key string) (any, error) {
+	v, _, err := f.GetWithTTL(ctx, key)
+	return v, err
+}
+
+func (f *FakeCache) GetWithTTL(_ context.Context, key string) (any, time.Duration, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, 0, f.Err
+	}
+	it, ok := f.items[key]
+	if !ok || !f.alive(it) {
+		return nil, 0, errors.Wrapf(cache.ErrNotFound, "key %s", key)
+	}
+	var ttl time.Duration
+	if !it.expireAt.IsZero() {
+		ttl = it.expireAt.Sub(f.now)
+	}
+	return it.value, ttl, nil
+}
+
+func (f *FakeCache) Set(_ context.Context, key string, value any, options ...cache.Option) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	it := fakeItem{value: value}
+	if opts := cache.ApplyOptions(options...); opts.Expiration > 0 {
+		it.expireAt = f.now.Add(opts.Expiration)
+	}
+	f.items[key] = it
+	return nil
+}
+
+func (f *FakeCache) Delete(_ context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	delete(f.items, key)
+	return nil
+}
+
+func (f *FakeCache) Flush(_ context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	f.items = map[string]fakeItem{}
+	return nil
+}
+
+func (f *FakeCache) Ping(_ context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.Err
+}
+
+// FakeLimitStore ratelimit.Store 的测试实现, 每个 key 前 limit 次放行, 之后限流, 不随时间重置
+type FakeLimitStore struct {
+	mu    sync.Mutex
+	limit uint
+	hits  map[string]uint
+}
+
+var _ ratelimit.Store = (*FakeLimitStore)(nil)
+
+// NewFakeLimitStore 创建 FakeLimitStore
+func NewFakeLimitStore(limit uint) *FakeLimitStore {
+	return &FakeLimitStore{limit: limit, hits: map[string]uint{}}
+}
+
+// Hits 返回 key 的请求次数
+func (s *FakeLimitStore) Hits(key string) uint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[key]
+}
+
+// Reset 清空计数
+func (s *FakeLimitStore) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hits = map[string]uint{}
+}
+
+func (s *FakeLimitStore) Limit(key string, _ *gin.Context) ratelimit.Info {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hits[key]++
+	hits := s.hits[key]
+	info := ratelimit.Info{Limit: s.limit, ResetTime: time.Now().Add(time.Minute)}
+	if hits > s.limit {
+		info.RateLimited = true
+		return info
+	}
+	info.RemainingHits = s.limit - hits
+	return info
+}
@@ -0,0 +1,300 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// HealthKind 检查项参与的探针, 可按位组合
+type HealthKind int
+
+const (
+	// HealthReadiness 参与 /readyz, 失败时摘除流量
+	HealthReadiness HealthKind = 1 << iota
+	// HealthLiveness 参与 /livez, 失败时重启进程
+	HealthLiveness
+)
+
+// HealthLevel 检查项的重要程度
+type HealthLevel int
+
+const (
+	// HealthCritical 失败时探针返回 503
+	HealthCritical HealthLevel = iota
+	// HealthNonCritical 失败时仅标记 degraded, 探针仍返回 200
+	HealthNonCritical
+)
+
+func (l HealthLevel) String() string {
+	if l == HealthNonCritical {
+		return "non-critical"
+	}
+	return "critical"
+}
+
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusDegraded = "degraded"
+	HealthStatusFail     = "fail"
+
+	defaultHealthTimeout  = 3 * time.Second
+	defaultHealthCacheTTL = 5 * time.Second
+)
+
+var (
+	promHealthStatus = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: promNamespace,
+			Name:      "health_check_status",
+			Help:      "health check status, 1 for ok and 0 for failure",
+		}, []string{"name", "level"},
+	)
+	promHealthLatency = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: promNamespace,
+			Name:      "health_check_latency_seconds",
+			Help:      "health check latency in seconds of the last run",
+		}, []string{"name"},
+	)
+	// DefaultHealth 默认健康检查注册表, Config.Health 开启时使用
+	DefaultHealth = NewHealthRegistry()
+)
+
+// HealthCheckFunc 检查函数, 返回 nil 表示健康
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthCheck 健康检查项
+type HealthCheck struct {
+	Name  string
+	Check HealthCheckFunc
+	// Timeout 单次检查超时, 默认 3s
+	Timeout time.Duration
+	// Level 默认 HealthCritical
+	Level HealthLevel
+	// Kind 默认 HealthReadiness
+	Kind HealthKind
+	// CacheTTL 结果缓存时间, 默认 5s, 小于 0 表示不缓存
+	CacheTTL time.Duration
+}
+
+// HealthResult 单个检查项的结果
+type HealthResult struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Level     string    `json:"level"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMs int64     `json:"latency_ms"`
+	Cached    bool      `json:"cached"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// HealthReport 探针返回的整体结果
+type HealthReport struct {
+	Status string         `json:"status"`
+	Checks []HealthResult `json:"checks"`
+}
+
+type healthEntry struct {
+	check HealthCheck
+	mu    sync.Mutex
+	last  *HealthResult
+}
+
+// HealthRegistry 健康检查注册表
+type HealthRegistry struct {
+	mu      sync.RWMutex
+	entries map[string]*healthEntry
+}
+
+// NewHealthRegistry 创建健康检查注册表
+func NewHealthRegistry() *HealthRegistry {
+	return &HealthRegistry{entries: map[string]*healthEntry{}}
+}
+
+// Register 注册检查项, 同名检查项会被替换
+func (h *HealthRegistry) Register(check HealthCheck) error {
+	if check.Name == "" || check.Check == nil {
+		return errors.New("exgin: health check name and func are required")
+	}
+	if check.Timeout <= 0 {
+		check.Timeout = defaultHealthTimeout
+	}
+	if check.Kind == 0 {
+		check.Kind = HealthReadiness
+	}
+	if check.CacheTTL == 0 {
+		check.CacheTTL = defaultHealthCacheTTL
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries[check.Name] = &healthEntry{check: check}
+	return nil
+}
+
+// Unregister 移除检查项
+func (h *HealthRegistry) Unregister(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.entries, name)
+	promHealthStatus.DeletePartialMatch(prometheus.Labels{"name": name})
+	promHealthLatency.DeleteLabelValues(name)
+}
+
+// Run 并发执行匹配 kind 的检查项, kind 为 0 时执行全部
+func (h *HealthRegistry) Run(ctx context.Context, kind HealthKind) HealthReport {
+	h.mu.RLock()
+	entries := make([]*healthEntry, 0, len(h.entries))
+	for _, e := range h.entries {
+		if kind == 0 || e.check.Kind&kind != 0 {
+			entries = append(entries, e)
+		}
+	}
+	h.mu.RUnlock()
+
+	results := make([]HealthResult, len(entries))
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e *healthEntry) {
+			defer wg.Done()
+			results[i] = e.run(ctx)
+		}(i, e)
+	}
+	wg.Wait()
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	report := HealthReport{Status: HealthStatusOK, Checks: results}
+	for _, r := range results {
+		if r.Status == HealthStatusOK {
+			continue
+		}
+		if r.Level == HealthCritical.String() {
+			report.Status = HealthStatusFail
+			break
+		}
+		report.Status = HealthStatusDegraded
+	}
+	return report
+}
+
+func (e *healthEntry) run(ctx context.Context) HealthResult {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.last != nil && e.check.CacheTTL > 0 && time.Since(e.last.CheckedAt) < e.check.CacheTTL {
+		r := *e.last
+		r.Cached = true
+		return r
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.check.Timeout)
+	defer cancel()
+	start := time.Now()
+	err := safeHealthCheck(ctx, e.check.Check)
+	latency := time.Since(start)
+
+	r := HealthResult{
+		Name:      e.check.Name,
+		Status:    HealthStatusOK,
+		Level:     e.check.Level.String(),
+		LatencyMs: latency.Milliseconds(),
+		CheckedAt: start,
+	}
+	status := 1.0
+	if err != nil {
+		r.Status = HealthStatusFail
+		r.Error = err.Error()
+		status = 0
+	}
+	promHealthStatus.WithLabelValues(r.Name, r.Level).Set(status)
+	promHealthLatency.WithLabelValues(r.Name).Set(latency.Seconds())
+	e.last = &r
+	return r
+}
+
+// safeHealthCheck 执行检查函数, 超时或 panic 都视为失败
+func safeHealthCheck(ctx context.Context, fn HealthCheckFunc) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				done <- errors.Newf("health check panic: %v", err)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Handler 返回指定探针的 gin handler, 任一 critical 检查失败时返回 503
+func (h *HealthRegistry) Handler(kind HealthKind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := h.Run(c.Request.Context(), kind)
+		code := http.StatusOK
+		if report.Status == HealthStatusFail {
+			code = http.StatusServiceUnavailable
+		}
+		c.JSON(code, report)
+	}
+}
+
+// RegisterRoutes 注册 /healthz, /readyz, /livez 到路由, prefix 可为空
+func (h *HealthRegistry) RegisterRoutes(r gin.IRoutes, prefix string) {
+	r.GET(prefix+"/healthz", h.Handler(0))
+	r.GET(prefix+"/readyz", h.Handler(HealthReadiness))
+	r.GET(prefix+"/livez", h.Handler(HealthLiveness))
+}
+
+// RegisterHealthCheck 注册到 DefaultHealth
+func RegisterHealthCheck(check HealthCheck) error {
+	return DefaultHealth.Register(check)
+}
+
+// Pinger 可探活的组件, cache.Cache 和 exkube.Client 均已实现
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// PingCheck 使用组件的 Ping 方法作为检查函数
+func PingCheck(p Pinger) HealthCheckFunc {
+	return p.Ping
+}
+
+// DBPingCheck 数据库探活, 适用于 *sql.DB (gorm 可通过 db.DB() 获取)
+func DBPingCheck(db interface {
+	PingContext(ctx context.Context) error
+}) HealthCheckFunc {
+	return db.PingContext
+}
+
+// DiskSpaceCheck 检查 path 所在磁盘剩余空间不低于 minFreeBytes
+func DiskSpaceCheck(path string, minFreeBytes uint64) HealthCheckFunc {
+	return func(_ context.Context) error {
+		free, err := diskFree(path)
+		if err != nil {
+			return err
+		}
+		if free < minFreeBytes {
+			return errors.Newf("disk %s free %d bytes, less than %d", path, free, minFreeBytes)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,17 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+//go:build !linux && !darwin && !freebsd
+// +build !linux,!darwin,!freebsd
+
+package exgin
+
+import "github.com/cockroachdb/errors"
+
+// diskFree 当前平台不支持磁盘空间检查
+func diskFree(_ string) (uint64, error) {
+	return 0, errors.New("exgin: disk space check is not supported on this platform")
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ergoapi/util/cache"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func doHealth(r *gin.Engine, path string) (int, HealthReport) {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	var report HealthReport
+	_ = json.Unmarshal(w.Body.Bytes(), &report)
+	return w.Code, report
+}
+
+func TestHealthRegistryProbes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewHealthRegistry()
+	require.NoError(t, h.Register(HealthCheck{Name: "cache", Check: PingCheck(cache.NewGoCache()), Kind: HealthReadiness | HealthLiveness}))
+	require.NoError(t, h.Register(HealthCheck{
+		Name:  "search",
+		Level: HealthNonCritical,
+		Check: func(context.Context) error { return errors.New("search down") },
+	}))
+	r := gin.New()
+	h.RegisterRoutes(r, "")
+
+	code, report := doHealth(r, "/livez")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthStatusOK, report.Status)
+	assert.Len(t, report.Checks, 1)
+
+	code, report = doHealth(r, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthStatusDegraded, report.Status)
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "search down", report.Checks[1].Error)
+
+	require.NoError(t, h.Register(HealthCheck{
+		Name:    "db",
+		Timeout: 10 * time.Millisecond,
+		Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}))
+	code, report = doHealth(r, "/healthz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, HealthStatusFail, report.Status)
+	assert.Len(t, report.Checks, 3)
+}
+
+func TestHealthCheckCache(t *testing.T) {
+	var calls atomic.Int32
+	h := NewHealthRegistry()
+	require.NoError(t, h.Register(HealthCheck{
+		Name:     "counter",
+		CacheTTL: time.Minute,
+		Check: func(context.Context) error {
+			calls.Add(1)
+			return nil
+		},
+	}))
+	first := h.Run(context.Background(), 0)
+	second := h.Run(context.Background(), 0)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.False(t, first.Checks[0].Cached)
+	assert.True(t, second.Checks[0].Cached)
+}
+
+func TestHealthCheckPanic(t *testing.T) {
+	h := NewHealthRegistry()
+	require.NoError(t, h.Register(HealthCheck{
+		Name:  "boom",
+		Check: func(context.Context) error { panic("boom") },
+	}))
+	report := h.Run(context.Background(), HealthReadiness)
+	assert.Equal(t, HealthStatusFail, report.Status)
+	assert.Contains(t, report.Checks[0].Error, "boom")
+}
+
+func TestDiskSpaceCheck(t *testing.T) {
+	assert.NoError(t, DiskSpaceCheck(t.TempDir(), 1)(context.Background()))
+	assert.Error(t, DiskSpaceCheck(t.TempDir(), ^uint64(0))(context.Background()))
+}
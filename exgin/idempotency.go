@@ -0,0 +1,202 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ergoapi/util/cache"
+	"github.com/ergoapi/util/exhash"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	idempotencyProcessing = "processing"
+	idempotencyDone       = "done"
+)
+
+// IdempotencyOptions Idempotency 中间件配置
+type IdempotencyOptions struct {
+	// Cache 存储响应, 默认 cache.Instance
+	Cache cache.Cache
+	// Header 默认 Idempotency-Key
+	Header string
+	// Methods 默认 POST, PUT, PATCH
+	Methods []string
+	// TTL 响应保存时间, 默认 24h
+	TTL time.Duration
+	// LockTTL 处理中标记的保存时间, 防止进程崩溃后 key 永久不可用, 默认 1m
+	LockTTL time.Duration
+	// KeyFunc 生成缓存 key, 可按用户或租户隔离, 默认 method + path + key
+	KeyFunc func(c *gin.Context, key string) string
+	// MaxBodySize 参与指纹计算的请求体上限, 超出返回 413, 默认 10MB
+	MaxBodySize int64
+}
+
+type idempotencyRecord struct {
+	State       string              `json:"state"`
+	Fingerprint string              `json:"fingerprint"`
+	Status      int                 `json:"status,omitempty"`
+	Header      map[string][]string `json:"header,omitempty"`
+	Body        []byte              `json:"body,omitempty"`
+}
+
+// Idempotency 按 Idempotency-Key 头保证写请求只执行一次
+// 首次响应连同请求指纹存入缓存, 重放时直接返回; 同 key 请求仍在处理中返回 409, 请求体不同返回 422
+// 5xx 响应不会被缓存, 客户端可使用同一 key 重试
+// 缓存读取失败(非未命中)或写入处理中标记失败时返回 503, 不在无保护的情况下执行请求
+// 注意: cache.Cache 没有原子 SetNX, 进程内通过锁保证互斥, 多实例间仍存在极小的竞争窗口
+func Idempotency(opts *IdempotencyOptions) gin.HandlerFunc {
+	o := IdempotencyOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Header == "" {
+		o.Header = "Idempotency-Key"
+	}
+	if len(o.Methods) == 0 {
+		o.Methods = []string{http.MethodPost, http.MethodPut, http.MethodPatch}
+	}
+	if o.TTL <= 0 {
+		o.TTL = 24 * time.Hour
+	}
+	if o.LockTTL <= 0 {
+		o.LockTTL = time.Minute
+	}
+	if o.MaxBodySize <= 0 {
+		o.MaxBodySize = 10 << 20
+	}
+	if o.KeyFunc == nil {
+		o.KeyFunc = func(c *gin.Context, key string) string {
+			return c.Request.Method + ":" + c.Request.URL.Path + ":" + key
+		}
+	}
+	methods := make(map[string]struct{}, len(o.Methods))
+	for _, m := range o.Methods {
+		methods[m] = struct{}{}
+	}
+	var locks sync.Map
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(o.Header)
+		if _, ok := methods[c.Request.Method]; !ok || key == "" {
+			c.Next()
+			return
+		}
+		store := o.Cache
+		if store == nil {
+			store = cache.Instance
+		}
+		if store == nil {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, o.MaxBodySize+1))
+		if err != nil {
+			if isBodyTooLarge(err) {
+				GinsAbort(c, http.StatusRequestEntityTooLarge, "请求体过大")
+				return
+			}
+			GinsAbort(c, http.StatusBadRequest, "读取请求体失败")
+			return
+		}
+		if int64(len(body)) > o.MaxBodySize {
+			GinsAbort(c, http.StatusRequestEntityTooLarge, "请求体过大")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		// 请求结束后仍需写入或清理缓存, 不跟随请求取消
+		ctx := context.WithoutCancel(c.Request.Context())
+		cacheKey := "idempotency:" + o.KeyFunc(c, key)
+		fingerprint := exhash.GenSha256(c.Request.Method + "\n" + c.Request.URL.RequestURI() + "\n" + string(body))
+
+		mu, _ := locks.LoadOrStore(cacheKey, &sync.Mutex{})
+		mu.(*sync.Mutex).Lock()
+		var record idempotencyRecord
+		err = cache.GetJSON(ctx, store, cacheKey, &record)
+		if err != nil && !errors.Is(err, cache.ErrNotFound) {
+			mu.(*sync.Mutex).Unlock()
+			locks.Delete(cacheKey)
+			logrus.Warnf("idempotency: load key %s failed: %v", cacheKey, err)
+			GinsAbort(c, http.StatusServiceUnavailable, "幂等记录暂不可用, 请稍后重试")
+			return
+		}
+		if err == nil {
+			mu.(*sync.Mutex).Unlock()
+			switch {
+			case record.Fingerprint != fingerprint:
+				GinsAbort(c, http.StatusUnprocessableEntity, "Idempotency-Key 已用于不同的请求")
+			case record.State == idempotencyProcessing:
+				GinsAbort(c, http.StatusConflict, "相同 Idempotency-Key 的请求正在处理中")
+			default:
+				replayIdempotent(c, &record)
+			}
+			return
+		}
+		err = cache.SetJSON(ctx, store, cacheKey, idempotencyRecord{
+			State:       idempotencyProcessing,
+			Fingerprint: fingerprint,
+		}, cache.WithExpiration(o.LockTTL))
+		mu.(*sync.Mutex).Unlock()
+		locks.Delete(cacheKey)
+		if err != nil {
+			logrus.Warnf("idempotency: lock key %s failed: %v", cacheKey, err)
+			GinsAbort(c, http.StatusServiceUnavailable, "幂等记录暂不可用, 请稍后重试")
+			return
+		}
+
+		stored := false
+		defer func() {
+			if !stored {
+				_ = store.Delete(ctx, cacheKey)
+			}
+		}()
+		w := &bodyCaptureWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = w
+		c.Next()
+
+		status := w.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		header := w.Header().Clone()
+		header.Del("X-Trace-Id")
+		err = cache.SetJSON(ctx, store, cacheKey, idempotencyRecord{
+			State:       idempotencyDone,
+			Fingerprint: fingerprint,
+			Status:      status,
+			Header:      header,
+			Body:        w.body.Bytes(),
+		}, cache.WithExpiration(o.TTL))
+		if err != nil {
+			logrus.Warnf("idempotency: save key %s failed: %v", cacheKey, err)
+			return
+		}
+		stored = true
+	}
+}
+
+func replayIdempotent(c *gin.Context, record *idempotencyRecord) {
+	for k, vs := range record.Header {
+		for _, v := range vs {
+			c.Writer.Header().Add(k, v)
+		}
+	}
+	c.Header("Idempotent-Replayed", "true")
+	c.Status(record.Status)
+	_, _ = c.Writer.Write(record.Body)
+	c.Abort()
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ergoapi/util/cache"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	entered := make(chan struct{})
+	r := gin.New()
+	r.Use(Idempotency(&IdempotencyOptions{Cache: cache.NewGoCache(cache.WithExpiration(time.Minute))}))
+	r.POST("/orders", func(c *gin.Context) {
+		n := calls.Add(1)
+		c.Header("X-Order", "created")
+		SucessResponse(c, n)
+	})
+	r.POST("/slow", func(c *gin.Context) {
+		close(entered)
+		<-release
+		SucessResponse(c, "ok")
+	})
+	r.POST("/fail", func(c *gin.Context) {
+		calls.Add(1)
+		GinsAbort(c, http.StatusInternalServerError, "boom")
+	})
+
+	do := func(path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := do("/orders", "k1", `{"amount":1}`)
+	assert.Equal(t, http.StatusOK, first.Code)
+	replay := do("/orders", "k1", `{"amount":1}`)
+	assert.Equal(t, http.StatusOK, replay.Code)
+	assert.Equal(t, first.Body.String(), replay.Body.String())
+	assert.Equal(t, "true", replay.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, "created", replay.Header().Get("X-Order"))
+	assert.Equal(t, int32(1), calls.Load())
+
+	assert.Equal(t, http.StatusUnprocessableEntity, do("/orders", "k1", `{"amount":2}`).Code)
+
+	do("/orders", "", `{"amount":1}`)
+	assert.Equal(t, int32(2), calls.Load())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		do("/slow", "k2", "")
+	}()
+	<-entered
+	assert.Equal(t, http.StatusConflict, do("/slow", "k2", "").Code)
+	close(release)
+	<-done
+
+	calls.Store(0)
+	do("/fail", "k3", "")
+	do("/fail", "k3", "")
+	assert.Equal(t, int32(2), calls.Load(), "5xx responses are not cached")
+}
+
+// flakyCache 可单独让 Get 或 Set 失败, 模拟缓存故障
+type flakyCache struct {
+	cache.Cache
+	getErr error
+	setErr error
+}
+
+func (f *flakyCache) Get(ctx context.Context, key string) (any, error) {
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
+	return f.Cache.Get(ctx, key)
+}
+
+func (f *flakyCache) Set(ctx context.Context, key string, value any, options ...cache.Option) error {
+	if f.setErr != nil {
+		return f.setErr
+	}
+	return f.Cache.Set(ctx, key, value, options...)
+}
+
+func TestIdempotencyFailsClosed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var calls atomic.Int32
+	store := &flakyCache{Cache: cache.NewGoCache()}
+	newEngine := func(mws ...gin.HandlerFunc) *gin.Engine {
+		r := gin.New()
+		r.Use(mws...)
+		r.POST("/pay", func(c *gin.Context) {
+			calls.Add(1)
+			SucessResponse(c, "ok")
+		})
+		return r
+	}
+	do := func(r *gin.Engine, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/pay", strings.NewReader(body))
+		// 不带 Content-Length, 只能在读取过程中判断大小
+		req.ContentLength = -1
+		req.Header.Set("Idempotency-Key", "k1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	r := newEngine(Idempotency(&IdempotencyOptions{Cache: store, MaxBodySize: 32}))
+	store.getErr = errors.New("redis down")
+	assert.Equal(t, http.StatusServiceUnavailable, do(r, `{}`))
+	store.getErr = nil
+	store.setErr = errors.New("redis down")
+	assert.Equal(t, http.StatusServiceUnavailable, do(r, `{}`))
+	store.setErr = nil
+	assert.Equal(t, http.StatusRequestEntityTooLarge, do(r, strings.Repeat("a", 40)))
+	// BodyLimit 在读取过程中返回的 *http.MaxBytesError
+	limited := newEngine(BodyLimit(16), Idempotency(&IdempotencyOptions{Cache: store}))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, do(limited, strings.Repeat("a", 20)))
+	assert.Equal(t, int32(0), calls.Load())
+
+	assert.Equal(t, http.StatusOK, do(r, `{}`))
+	assert.Equal(t, int32(1), calls.Load())
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Timeout 为请求设置截止时间, 通过 c.Request.Context() 向下游传递取消信号
+// handler 因超时返回且尚未写响应时返回 504, 请求被取消时返回 503
+// 注意: 超时是协作式的, handler 需要使用请求的 context 才能及时退出
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		if c.Writer.Written() {
+			return
+		}
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			GinsAbort(c, http.StatusGatewayTimeout, "请求超时")
+		case errors.Is(ctx.Err(), context.Canceled):
+			GinsAbort(c, http.StatusServiceUnavailable, "请求已取消")
+		}
+	}
+}
+
+// BodyLimit 限制请求体大小, 超出时返回 413
+// Content-Length 未知时通过 http.MaxBytesReader 在读取过程中限制
+func BodyLimit(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > limit {
+			GinsAbort(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("请求体超过限制 %d 字节", limit))
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// isBodyTooLarge 判断是否为 BodyLimit 触发的读取错误
+func isBodyTooLarge(err error) bool {
+	var maxErr *http.MaxBytesError
+	return errors.As(err, &maxErr)
+}
+
+// slowRequestKey SlowRequest 在 gin.Context 中的标记, ExLog 据此跳过重复的慢请求警告
+const slowRequestKey = "ex-slow-request"
+
+// SlowRequestOptions SlowRequest 配置
+type SlowRequestOptions struct {
+	// Threshold 慢请求阈值, 默认 3s
+	Threshold time.Duration
+	// SnapshotDir 不为空时, 慢请求触发 goroutine 快照写入该目录
+	SnapshotDir string
+	// SnapshotInterval 两次快照的最小间隔, 默认 1m
+	SnapshotInterval time.Duration
+	// OnSlow 慢请求回调
+	OnSlow func(c *gin.Context, latency time.Duration)
+}
+
+// SlowRequest 记录超过阈值的请求, 带上 traceID, 可选触发 goroutine 快照
+// 安装后 ExLog 不再输出自身的慢请求警告, 避免同一请求告警两次
+func SlowRequest(opts *SlowRequestOptions) gin.HandlerFunc {
+	o := SlowRequestOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Threshold <= 0 {
+		o.Threshold = defaultGinSlowThreshold
+	}
+	if o.SnapshotInterval <= 0 {
+		o.SnapshotInterval = time.Minute
+	}
+	var (
+		mu           sync.Mutex
+		lastSnapshot time.Time
+	)
+	return func(c *gin.Context) {
+		c.Set(slowRequestKey, true)
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+		if latency < o.Threshold {
+			return
+		}
+		traceID := getTraceID(c)
+		fields := logrus.Fields{
+			"traceID": traceID,
+			"method":  c.Request.Method,
+			"path":    c.Request.URL.Path,
+			"route":   c.FullPath(),
+			"latency": latency,
+			"Tag":     "exgin",
+		}
+		if o.SnapshotDir != "" {
+			mu.Lock()
+			if time.Since(lastSnapshot) >= o.SnapshotInterval {
+				lastSnapshot = time.Now()
+				if file, err := goroutineSnapshot(o.SnapshotDir, traceID); err != nil {
+					logrus.Warnf("slow request: goroutine snapshot failed: %v", err)
+				} else {
+					fields["snapshot"] = file
+				}
+			}
+			mu.Unlock()
+		}
+		logrus.WithFields(fields).Warnf("slow request >= %v", o.Threshold)
+		if o.OnSlow != nil {
+			o.OnSlow(c, latency)
+		}
+	}
+}
+
+func goroutineSnapshot(dir, traceID string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	if traceID == "" {
+		traceID = "none"
+	}
+	name := filepath.Join(dir, fmt.Sprintf("goroutine-%s-%s.txt", time.Now().Format("20060102T150405"), filepath.Base(traceID)))
+	f, err := os.Create(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if err := pprof.Lookup("goroutine").WriteTo(f, 1); err != nil {
+		return "", err
+	}
+	return name, nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/slow", Timeout(20*time.Millisecond), func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+		case <-time.After(time.Second):
+			SucessResponse(c, "late")
+		}
+	})
+	r.GET("/fast", Timeout(time.Second), func(c *gin.Context) { SucessResponse(c, "ok") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := Init(&Config{MaxBodySize: 8, NoCors: true})
+	r.Use(ExRecovery())
+	r.POST("/bind", func(c *gin.Context) {
+		var v map[string]any
+		Bind(c, &v)
+		SucessResponse(c, v)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/bind", strings.NewReader(`{"a":"0123456789"}`)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, "known content length")
+
+	req := httptest.NewRequest(http.MethodPost, "/bind", strings.NewReader(`{"a":"0123456789"}`))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, "unknown content length")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/bind", strings.NewReader(`{"a":1}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSlowRequestSnapshot(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	var slow int
+	r := gin.New()
+	r.Use(SlowRequest(&SlowRequestOptions{
+		Threshold:   time.Millisecond,
+		SnapshotDir: dir,
+		OnSlow:      func(*gin.Context, time.Duration) { slow++ },
+	}))
+	r.GET("/", func(c *gin.Context) {
+		time.Sleep(5 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+	for i := 0; i < 2; i++ {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	assert.Equal(t, 2, slow)
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "snapshots are rate limited")
+}
@@ -7,6 +7,7 @@
 package exgin
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 	"net/http"
@@ -17,20 +18,17 @@ import (
 	"time"
 
 	"github.com/ergoapi/util/environ"
+	"github.com/ergoapi/util/exctx"
 	errors "github.com/ergoapi/util/exerror"
 	"github.com/ergoapi/util/exid"
 	"github.com/gin-gonic/gin"
-	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/locales/en"
 	"github.com/go-playground/locales/zh"
 	ut "github.com/go-playground/universal-translator"
-	"github.com/go-playground/validator/v10"
-	en_translations "github.com/go-playground/validator/v10/translations/en"
-	zh_translations "github.com/go-playground/validator/v10/translations/zh"
 	"github.com/sirupsen/logrus"
 )
 
-var uni = ut.New(en.New(), zh.New())
+var uni = ut.New(en.New(), en.New(), zh.New())
 
 // exCors ex cors middleware
 func exCors() gin.HandlerFunc {
@@ -63,17 +61,28 @@ func exTraceID() gin.HandlerFunc {
 		}
 		g.Header("X-Trace-Id", traceID)
 		g.Set("ex-trace-id", traceID)
-		// per-request logger，避免污染全局 logrus hook 列表
-		g.Set("ex-logger", logrus.WithFields(logrus.Fields{
-			"traceID": traceID,
-			"Tag":     "exgin",
-		}))
+		// 写入 request context, exctx.Logger 与 ExLog 据此关联日志和 span
+		trace := &exctx.TraceContext{}
+		trace.TraceID = traceID
+		g.Request = g.Request.WithContext(exctx.SetTraceContext(g.Request.Context(), trace))
 		g.Next()
 	}
 }
 
 // ExLog log middleware
 func ExLog(skip ...string) gin.HandlerFunc {
+	return ExLogWithOptions(&LogOptions{Skip: skip})
+}
+
+// ExLogWithOptions log middleware, 可选记录脱敏后的请求体/响应体
+func ExLogWithOptions(opts *LogOptions) gin.HandlerFunc {
+	o := LogOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.setDefaults()
+	opts = &o
+	redactor := newBodyRedactor(opts.RedactFields, opts.RedactHeaders)
 	return func(c *gin.Context) {
 		start := time.Now()
 		host := Host(c)
@@ -81,18 +90,41 @@ func ExLog(skip ...string) gin.HandlerFunc {
 		method := c.Request.Method
 		ua := c.Request.UserAgent()
 		query := c.Request.URL.RawQuery
-		c.Next()
-		for _, s := range skip {
-			if strings.HasPrefix(path, s) {
-				return
+		if matchPathPrefix(path, opts.Skip) {
+			c.Next()
+			return
+		}
+		ctx := c.Request.Context()
+		if parent, ok := exctx.ParseTraceparent(c.GetHeader("traceparent")); ok {
+			ctx = exctx.SetTraceContext(ctx, parent)
+		} else if traceID := c.GetHeader("X-Trace-Id"); traceID != "" {
+			// exTraceID 生成的 UUID 作为 trace ID, 日志与 span 关联到同一 trace
+			parent := &exctx.TraceContext{}
+			parent.TraceID = traceID
+			ctx = exctx.SetTraceContext(ctx, parent)
+		}
+		ctx, span := exctx.StartSpan(ctx, method+" "+path, exctx.WithSpanKind(exctx.SpanKindServer))
+		c.Request = c.Request.WithContext(ctx)
+		captureBody := opts.LogBody && (len(opts.BodyPaths) == 0 || matchPathPrefix(path, opts.BodyPaths))
+		var (
+			reqBody      []byte
+			reqTruncated bool
+			bw           *bodyCaptureWriter
+		)
+		if captureBody {
+			if matchContentType(c.ContentType(), opts.BodyContentTypes) {
+				reqBody, reqTruncated = peekRequestBody(c, opts.MaxBodySize)
 			}
+			bw = &bodyCaptureWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}, limit: opts.MaxBodySize}
+			c.Writer = bw
 		}
+		c.Next()
 		end := time.Now()
 		latency := end.Sub(start)
 		if len(query) == 0 {
 			query = " - "
 		}
-		if latency > defaultGinSlowThreshold {
+		if opts.SlowThreshold > 0 && latency > opts.SlowThreshold && !c.GetBool(slowRequestKey) {
 			logrus.Warnf("[msg] api %v query %v", path, latency)
 		}
 		statuscode := c.Writer.Status()
@@ -102,12 +134,7 @@ func ExLog(skip ...string) gin.HandlerFunc {
 		xffIP := c.Writer.Header().Get("X-Forwarded-For")
 		readIP := c.Writer.Header().Get("X-Real-Ip")
 		referer := c.Request.Referer()
-		logger := logrus.StandardLogger().WithContext(c.Request.Context())
-		if v, exists := c.Get("ex-logger"); exists {
-			if entry, ok := v.(*logrus.Entry); ok {
-				logger = entry
-			}
-		}
+		logger := exctx.Logger(c.Request.Context()).WithField("Tag", "exgin")
 		fields := logrus.Fields{
 			"statuscode": statuscode,
 			"bodysize":   bodysize,
@@ -122,6 +149,19 @@ func ExLog(skip ...string) gin.HandlerFunc {
 			"ua":         ua,
 			"referer":    referer,
 		}
+		if opts.LogHeaders {
+			fields["req_headers"] = redactor.header(c.Request.Header)
+		}
+		if captureBody {
+			if len(reqBody) > 0 {
+				fields["req_body"] = truncatedBody(redactor.body(c.ContentType(), reqBody), reqTruncated)
+			}
+			respType := bw.Header().Get("Content-Type")
+			if bw.body.Len() > 0 && matchContentType(respType, opts.BodyContentTypes) {
+				fields["resp_body"] = truncatedBody(redactor.body(respType, bw.body.Bytes()), bw.truncated)
+			}
+		}
+		endServerSpan(c, span, statuscode)
 		if len(c.Errors) > 0 || c.Writer.Status() >= 500 {
 			logger.WithFields(fields).Warnf("query: %v  <= err: %v", query, c.Errors.String())
 		} else {
@@ -134,15 +174,47 @@ func ExLog(skip ...string) gin.HandlerFunc {
 	}
 }
 
+// endServerSpan 按路由模板命名 span 并记录响应状态
+func endServerSpan(c *gin.Context, span *exctx.Span, status int) {
+	if route := c.FullPath(); route != "" {
+		span.SetName(c.Request.Method + " " + route)
+		span.SetAttribute("http.route", route)
+	}
+	span.SetAttributes(map[string]any{
+		"http.request.method":       c.Request.Method,
+		"url.path":                  c.Request.URL.Path,
+		"http.response.status_code": status,
+		"client.address":            c.ClientIP(),
+	})
+	if len(c.Errors) > 0 {
+		span.RecordError(c.Errors.Last())
+	}
+	if status >= http.StatusInternalServerError {
+		span.SetStatus(exctx.StatusError, http.StatusText(status))
+	}
+	span.End()
+}
+
+func truncatedBody(body string, truncated bool) string {
+	if truncated {
+		return body + "...(truncated)"
+	}
+	return body
+}
+
 // ExRecovery recovery
 func ExRecovery() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
+				if res, ok := err.(ValidationErrors); ok {
+					c.AbortWithStatusJSON(400, newResponse(400, getTraceID(c), res, res.Error()))
+					return
+				}
 				if res, ok := err.(errors.ErgoError); ok {
 					code := 400
-					if strings.Contains(res.Message, "unauth") {
-						code = 401
+					if res.Code != 0 {
+						code = res.Code
 					}
 					GinsAbort(c, code, res.Message)
 					return
@@ -184,23 +256,11 @@ func ExHackHeader() gin.HandlerFunc {
 	}
 }
 
-// Translations .
+// Translations 根据 Accept-Language(或 locale 头) 选择校验错误的翻译器, 默认中文
 func Translations() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		locale := c.GetHeader("locale")
-		trans, _ := uni.GetTranslator(locale)
-		v, ok := binding.Validator.Engine().(*validator.Validate)
-		if ok {
-			switch locale {
-			case "zh":
-				_ = zh_translations.RegisterDefaultTranslations(v, trans)
-			case "en":
-				_ = en_translations.RegisterDefaultTranslations(v, trans)
-			default:
-				_ = zh_translations.RegisterDefaultTranslations(v, trans)
-			}
-			c.Set("trans", trans)
-		}
+		setupValidator()
+		c.Set(transKey, translator(c))
 		c.Next()
 	}
 }
@@ -0,0 +1,523 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"html/template"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	errors "github.com/ergoapi/util/exerror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// OpenAPIInfo 文档基本信息
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Parameter path/query/header 参数
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// MediaType 请求体或响应体内容
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// RequestBody 请求体
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// OpenAPIResponse 响应
+type OpenAPIResponse struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Operation 单个接口
+type Operation struct {
+	OperationID string                      `json:"operationId,omitempty"`
+	Summary     string                      `json:"summary,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Tags        []string                    `json:"tags,omitempty"`
+	Deprecated  bool                        `json:"deprecated,omitempty"`
+	Parameters  []Parameter                 `json:"parameters,omitempty"`
+	RequestBody *RequestBody                `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+	Security    []map[string][]string       `json:"security,omitempty"`
+}
+
+// OpenAPIComponents 可复用的 schema 和认证方式
+type OpenAPIComponents struct {
+	Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+	SecuritySchemes map[string]map[string]any `json:"securitySchemes,omitempty"`
+}
+
+// OpenAPIDocument OpenAPI 3.1 文档
+type OpenAPIDocument struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       OpenAPIInfo                      `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components OpenAPIComponents                `json:"components"`
+}
+
+// OpenAPIUI 文档页面类型
+type OpenAPIUI string
+
+const (
+	OpenAPIUINone    OpenAPIUI = ""
+	OpenAPIUISwagger OpenAPIUI = "swagger"
+	OpenAPIUIRedoc   OpenAPIUI = "redoc"
+
+	bearerAuthScheme = "bearerAuth"
+
+	// DefaultOpenAPIAssetBaseURL Swagger UI/Redoc 页面默认的静态资源地址
+	DefaultOpenAPIAssetBaseURL = "https://cdn.jsdelivr.net/npm"
+)
+
+var ginPathParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// OpenAPI 收集通过 Route 注册的接口并生成文档
+type OpenAPI struct {
+	mu     sync.RWMutex
+	doc    OpenAPIDocument
+	reg    *schemaRegistry
+	assets string
+}
+
+// NewOpenAPI 创建文档
+func NewOpenAPI(info OpenAPIInfo) *OpenAPI {
+	reg := newSchemaRegistry()
+	return &OpenAPI{
+		reg:    reg,
+		assets: DefaultOpenAPIAssetBaseURL,
+		doc: OpenAPIDocument{
+			OpenAPI:    "3.1.0",
+			Info:       info,
+			Paths:      map[string]map[string]*Operation{},
+			Components: OpenAPIComponents{Schemas: reg.schemas},
+		},
+	}
+}
+
+// MarshalJSON 输出文档 JSON
+func (o *OpenAPI) MarshalJSON() ([]byte, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return json.Marshal(&o.doc)
+}
+
+// SetAssetBaseURL 设置 Swagger UI/Redoc 页面静态资源地址, 默认从 jsDelivr CDN 加载
+// 离线或内网环境可将 npm 包 swagger-ui-dist@5、redoc@2 按 <base>/<包名@版本>/... 的目录结构自行托管后指向该地址
+func (o *OpenAPI) SetAssetBaseURL(base string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.assets = strings.TrimRight(base, "/")
+}
+
+// Serve 在 path/openapi.json 提供文档, ui 不为空时在 path 提供 Swagger UI 或 Redoc 页面
+// 页面静态资源默认从 CDN 加载, 离线环境使用 SetAssetBaseURL 指向自行托管的地址
+func (o *OpenAPI) Serve(r gin.IRouter, path string, ui OpenAPIUI) {
+	path = strings.TrimRight(path, "/")
+	specPath := path + "/openapi.json"
+	r.GET(specPath, func(c *gin.Context) {
+		data, err := o.MarshalJSON()
+		if err != nil {
+			ErrorResponse(c, http.StatusInternalServerError, err)
+			return
+		}
+		c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+	})
+	if ui == OpenAPIUINone {
+		return
+	}
+	page := template.Must(template.New("ui").Parse(openAPIPages[ui]))
+	r.GET(path, func(c *gin.Context) {
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusOK)
+		o.mu.RLock()
+		data := map[string]string{"Title": o.doc.Info.Title, "Spec": joinBasePath(r, specPath), "Assets": o.assets}
+		o.mu.RUnlock()
+		_ = page.Execute(c.Writer, data)
+	})
+}
+
+var openAPIPages = map[OpenAPIUI]string{
+	OpenAPIUISwagger: `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.Assets}}/swagger-ui-dist@5/swagger-ui.css"></head>
+<body><div id="swagger-ui"></div>
+<script src="{{.Assets}}/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>window.ui = SwaggerUIBundle({url: "{{.Spec}}", dom_id: "#swagger-ui"});</script>
+</body></html>`,
+	OpenAPIUIRedoc: `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body><redoc spec-url="{{.Spec}}"></redoc>
+<script src="{{.Assets}}/redoc@2/bundles/redoc.standalone.js"></script>
+</body></html>`,
+}
+
+// RouteOption 补充接口文档信息
+type RouteOption func(op *Operation)
+
+// WithSummary 接口摘要
+func WithSummary(summary string) RouteOption {
+	return func(op *Operation) { op.Summary = summary }
+}
+
+// WithDescription 接口描述
+func WithDescription(desc string) RouteOption {
+	return func(op *Operation) { op.Description = desc }
+}
+
+// WithTags 接口分组
+func WithTags(tags ...string) RouteOption {
+	return func(op *Operation) { op.Tags = append(op.Tags, tags...) }
+}
+
+// WithOperationID 自定义 operationId, 默认由 method 和 path 生成
+func WithOperationID(id string) RouteOption {
+	return func(op *Operation) { op.OperationID = id }
+}
+
+// WithDeprecated 标记接口已废弃
+func WithDeprecated() RouteOption {
+	return func(op *Operation) { op.Deprecated = true }
+}
+
+// WithBearerAuth 标记接口需要 JWT Bearer 认证
+func WithBearerAuth() RouteOption {
+	return func(op *Operation) {
+		op.Security = append(op.Security, map[string][]string{bearerAuthScheme: {}})
+	}
+}
+
+// WithListQuery 描述 ParseListQuery 支持的分页、排序和过滤参数
+func WithListQuery(opts *ListOptions) RouteOption {
+	o := ListOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.setDefaults()
+	return func(op *Operation) {
+		one, maxSize, defSize := 1.0, float64(o.MaxPageSize), o.DefaultPageSize
+		op.Parameters = append(op.Parameters,
+			Parameter{Name: "page", In: "query", Description: "页码, 从1开始",
+				Schema: &Schema{Type: "integer", Minimum: &one, Default: 1}},
+			Parameter{Name: "page_size", In: "query", Description: "每页条数",
+				Schema: &Schema{Type: "integer", Minimum: &one, Maximum: &maxSize, Default: defSize}},
+		)
+		if len(o.CursorSecret) > 0 {
+			op.Parameters = append(op.Parameters, Parameter{Name: "cursor", In: "query",
+				Description: "游标, 取自上一页的 next_cursor", Schema: &Schema{Type: "string"}})
+		}
+		if len(o.SortFields) > 0 {
+			op.Parameters = append(op.Parameters, Parameter{Name: "sort", In: "query",
+				Description: "排序字段, 逗号分隔, - 前缀表示倒序, 可选: " + strings.Join(o.SortFields, ", "),
+				Schema:      &Schema{Type: "string", Example: "-" + o.SortFields[0], Default: nilIfEmpty(o.DefaultSort)}})
+		}
+		for _, f := range o.FilterFields {
+			op.Parameters = append(op.Parameters, Parameter{Name: "filter[" + f + "]", In: "query",
+				Description: "按 " + f + " 过滤", Schema: &Schema{Type: "string"}})
+		}
+	}
+}
+
+func nilIfEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// Route 注册带类型的接口: 绑定并校验 uri/form/header/json 参数, 调用 handler, 按统一响应结构返回, 同时写入文档
+// Req 字段使用 uri/form/header tag 作为参数, 其余 json 字段作为请求体; binding 规则会映射为 schema 约束
+// handler 返回 ValidationErrors 时响应 400, 返回 exerror.ErgoError 时使用其 Code(默认 400), 其他错误响应 500
+func Route[Req, Resp any](o *OpenAPI, r gin.IRouter, method, path string, handler func(c *gin.Context, req *Req) (Resp, error), opts ...RouteOption) {
+	method = strings.ToUpper(method)
+	reqType := reflect.TypeOf((*Req)(nil)).Elem()
+	hasBody := method != http.MethodGet && method != http.MethodDelete && method != http.MethodHead && hasBodyFields(reqType)
+
+	r.Handle(method, path, func(c *gin.Context) {
+		req := new(Req)
+		if err := bindRoute(c, req, hasBody); err != nil {
+			code := http.StatusBadRequest
+			if isBodyTooLarge(err) {
+				code = http.StatusRequestEntityTooLarge
+			}
+			ErrorResponse(c, code, err)
+			return
+		}
+		resp, err := handler(c, req)
+		if err != nil {
+			ErrorResponse(c, routeErrorStatus(err), err)
+			return
+		}
+		SucessResponse(c, resp)
+	})
+
+	fullPath := joinBasePath(r, path)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	op := &Operation{
+		OperationID: operationID(method, fullPath),
+		Parameters:  o.parameters(reqType),
+		Responses:   map[string]*OpenAPIResponse{},
+	}
+	if hasBody {
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+			"application/json": {Schema: o.reg.schema(reqType)},
+		}}
+	}
+	for _, opt := range opts {
+		opt(op)
+	}
+	op.Responses["200"] = o.envelope("成功", o.reg.schema(reflect.TypeOf((*Resp)(nil)).Elem()))
+	op.Responses["400"] = o.envelope("参数不合法", o.reg.schema(reflect.TypeOf(ValidationErrors(nil))))
+	if len(op.Security) > 0 {
+		op.Responses["401"] = o.envelope("未认证", nil)
+		if o.doc.Components.SecuritySchemes == nil {
+			o.doc.Components.SecuritySchemes = map[string]map[string]any{}
+		}
+		o.doc.Components.SecuritySchemes[bearerAuthScheme] = map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"}
+	}
+	op.Responses["default"] = o.envelope("错误", nil)
+
+	docPath := ginPathParam.ReplaceAllString(fullPath, "{$1}")
+	if o.doc.Paths[docPath] == nil {
+		o.doc.Paths[docPath] = map[string]*Operation{}
+	}
+	o.doc.Paths[docPath][strings.ToLower(method)] = op
+}
+
+// envelope 统一响应结构, data 为 nil 时表示 null
+func (o *OpenAPI) envelope(desc string, data *Schema) *OpenAPIResponse {
+	if data == nil {
+		data = &Schema{Type: "null"}
+	}
+	return &OpenAPIResponse{Description: desc, Content: map[string]MediaType{
+		"application/json": {Schema: &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"code":      {Type: "integer", Format: "int32"},
+				"data":      data,
+				"message":   {Type: "string"},
+				"timestamp": {Type: "integer", Format: "int64"},
+				"traceId":   {Type: "string"},
+			},
+			Required: []string{"code", "data", "message", "timestamp", "traceId"},
+		}},
+	}}
+}
+
+// parameters 从 uri/form/header tag 生成参数
+func (o *OpenAPI) parameters(t reflect.Type) []Parameter {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.Tag.Get("json") == "" {
+			params = append(params, o.parameters(f.Type)...)
+			continue
+		}
+		for _, in := range []string{"uri", "form", "header"} {
+			tag := f.Tag.Get(in)
+			if tag == "" || tag == "-" {
+				continue
+			}
+			name, rest, _ := strings.Cut(tag, ",")
+			s := o.reg.fieldSchema(f)
+			if def, ok := strings.CutPrefix(rest, "default="); ok {
+				s.Default = parseTagValue(s, def)
+			}
+			p := Parameter{Name: name, In: map[string]string{"uri": "path", "form": "query", "header": "header"}[in],
+				Description: s.Description, Schema: s, Required: in == "uri" || isRequired(f.Tag.Get("binding"))}
+			s.Description = ""
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+func hasBodyFields(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Tag.Get("json") == "-" {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.Tag.Get("json") == "" {
+			if hasBodyFields(f.Type) {
+				return true
+			}
+			continue
+		}
+		if f.Tag.Get("json") != "" || f.Tag.Get("uri") == "" && f.Tag.Get("form") == "" && f.Tag.Get("header") == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// mapRequestParams 将 path、query、header 参数按 uri/form/header tag 绑定到 v, 不做校验
+// 每一轮只提供 tag 中声明过的 key, 避免 gin 对无 tag 字段按字段名回退绑定;
+// uri 最后绑定, path 参数优先于 query 和请求头
+func mapRequestParams(c *gin.Context, v any) error {
+	t := reflect.TypeOf(v)
+	query := c.Request.URL.Query()
+	form := make(map[string][]string)
+	for name := range tagNames(t, "form") {
+		if vs, ok := query[name]; ok {
+			form[name] = vs
+		}
+	}
+	if err := binding.MapFormWithTag(v, form, "form"); err != nil {
+		return err
+	}
+	headers := make(map[string][]string)
+	for name := range tagNames(t, "header") {
+		if vs := c.Request.Header.Values(name); len(vs) > 0 {
+			headers[name] = vs
+		}
+	}
+	if err := binding.MapFormWithTag(v, headers, "header"); err != nil {
+		return err
+	}
+	names := tagNames(t, "uri")
+	params := make(map[string][]string, len(c.Params))
+	for _, p := range c.Params {
+		if names[p.Key] {
+			params[p.Key] = []string{p.Value}
+		}
+	}
+	return binding.MapFormWithTag(v, params, "uri")
+}
+
+// tagNames 收集 t 中声明了 tag 的字段名, 规则与 gin 一致: 空名称使用字段名, 嵌套结构体递归
+func tagNames(t reflect.Type, tag string) map[string]bool {
+	names := map[string]bool{}
+	collectTagNames(t, tag, names, map[reflect.Type]bool{})
+	return names
+}
+
+func collectTagNames(t reflect.Type, tag string, names map[string]bool, seen map[reflect.Type]bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) || seen[t] {
+		return
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+		value, ok := f.Tag.Lookup(tag)
+		name, _, _ := strings.Cut(value, ",")
+		if name == "-" {
+			continue
+		}
+		if ok {
+			if name == "" {
+				name = f.Name
+			}
+			names[name] = true
+		}
+		collectTagNames(f.Type, tag, names, seen)
+	}
+}
+
+func bindRoute(c *gin.Context, req any, hasBody bool) error {
+	setupValidator()
+	// 先解析 body 再绑定参数, body 中的同名字段不能覆盖 path/query/header 参数
+	if hasBody && c.Request.Body != nil && c.Request.Body != http.NoBody {
+		if err := json.NewDecoder(c.Request.Body).Decode(req); err != nil && !stderrors.Is(err, io.EOF) {
+			if isBodyTooLarge(err) {
+				return err
+			}
+			return bindError(c, err)
+		}
+	}
+	if err := mapRequestParams(c, req); err != nil {
+		return bindError(c, err)
+	}
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return bindError(c, err)
+	}
+	return nil
+}
+
+func routeErrorStatus(err error) int {
+	var verrs ValidationErrors
+	if stderrors.As(err, &verrs) {
+		return http.StatusBadRequest
+	}
+	var ee *errors.ErgoError
+	if stderrors.As(err, &ee) {
+		if ee.Code != 0 {
+			return ee.Code
+		}
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func joinBasePath(r gin.IRouter, path string) string {
+	base := ""
+	if g, ok := r.(interface{ BasePath() string }); ok {
+		base = g.BasePath()
+	}
+	joined := strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+	if len(joined) > 1 && strings.HasSuffix(path, "/") && !strings.HasSuffix(joined, "/") {
+		joined += "/"
+	}
+	return joined
+}
+
+func operationID(method, path string) string {
+	parts := []string{strings.ToLower(method)}
+	for _, seg := range strings.Split(path, "/") {
+		seg = strings.TrimLeft(seg, ":*")
+		if seg != "" {
+			parts = append(parts, schemaNameReplacer.ReplaceAllString(seg, "_"))
+		}
+	}
+	return strings.Join(parts, "_")
+}
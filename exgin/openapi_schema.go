@@ -0,0 +1,305 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema OpenAPI 3.1 schema 子集
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Example              any                `json:"example,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+}
+
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	rawMessageType     = reflect.TypeOf(json.RawMessage{})
+	schemaNameReplacer = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+)
+
+// schemaRegistry 生成 schema, 命名结构体放入 components 并使用 $ref
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{schemas: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+// schemaName 结构体名, 泛型参数中的包路径会被去掉, 例如 Page[pkg.User] -> Page_User
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+	if i := strings.Index(name, "["); i >= 0 {
+		args := strings.Split(strings.TrimSuffix(name[i+1:], "]"), ",")
+		for j, a := range args {
+			if k := strings.LastIndex(a, "."); k >= 0 {
+				a = a[k+1:]
+			}
+			args[j] = a
+		}
+		name = name[:i] + "_" + strings.Join(args, "_")
+	}
+	return strings.Trim(schemaNameReplacer.ReplaceAllString(name, "_"), "_")
+}
+
+func (r *schemaRegistry) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		name, ok := r.names[t]
+		if !ok {
+			name = r.uniqueName(t)
+			r.names[t] = name
+			// 先占位, 支持递归引用
+			r.schemas[name] = &Schema{}
+			*r.schemas[name] = *r.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	// interface 等任意类型
+	return &Schema{}
+}
+
+// uniqueName 为 t 分配未被其他类型占用的组件名
+// 不同包的同名结构体或泛型参数去掉包路径后同名时, 后注册的追加包名, 仍冲突时追加序号
+func (r *schemaRegistry) uniqueName(t reflect.Type) string {
+	name := schemaName(t)
+	if _, taken := r.schemas[name]; !taken {
+		return name
+	}
+	if pkg := path.Base(t.PkgPath()); pkg != "." && pkg != "/" {
+		name += "_" + schemaNameReplacer.ReplaceAllString(pkg, "_")
+		if _, taken := r.schemas[name]; !taken {
+			return name
+		}
+	}
+	for i := 2; ; i++ {
+		candidate := name + "_" + strconv.Itoa(i)
+		if _, taken := r.schemas[candidate]; !taken {
+			return candidate
+		}
+	}
+}
+
+func (r *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	r.addFields(s, t)
+	return s
+}
+
+func (r *schemaRegistry) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == "-" {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && tag == "" && ft.Kind() == reflect.Struct {
+			r.addFields(s, ft)
+			continue
+		}
+		// uri/form/header 字段是参数, 不属于 body
+		if tag == "" && (f.Tag.Get("uri") != "" || f.Tag.Get("form") != "" || f.Tag.Get("header") != "") {
+			continue
+		}
+		name := tag
+		if name == "" {
+			name = f.Name
+		}
+		fs := r.fieldSchema(f)
+		s.Properties[name] = fs
+		if isRequired(f.Tag.Get("binding")) && !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// fieldSchema 字段 schema, 附加 doc/example tag 和 binding 校验规则
+func (r *schemaRegistry) fieldSchema(f reflect.StructField) *Schema {
+	base := r.schema(f.Type)
+	if base.Ref != "" {
+		if doc := f.Tag.Get("doc"); doc != "" {
+			// 3.1 允许 $ref 与 description 并列
+			return &Schema{Ref: base.Ref, Description: doc}
+		}
+		return base
+	}
+	s := *base
+	s.Description = f.Tag.Get("doc")
+	if ex := f.Tag.Get("example"); ex != "" {
+		s.Example = parseTagValue(&s, ex)
+	}
+	applyBinding(&s, f.Tag.Get("binding"))
+	return &s
+}
+
+func isRequired(binding string) bool {
+	for _, rule := range strings.Split(binding, ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyBinding 将 validator 规则映射为 schema 约束, 只处理常用规则, dive 之后的规则忽略
+func applyBinding(s *Schema, binding string) {
+	for _, rule := range strings.Split(binding, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			return
+		case "min", "gte":
+			setBound(s, param, true, false)
+		case "max", "lte":
+			setBound(s, param, false, false)
+		case "gt":
+			setBound(s, param, true, true)
+		case "lt":
+			setBound(s, param, false, true)
+		case "len":
+			setBound(s, param, true, false)
+			setBound(s, param, false, false)
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				s.Enum = append(s.Enum, parseTagValue(s, v))
+			}
+		case "email":
+			s.Format = "email"
+		case "url", "http_url":
+			s.Format = "uri"
+		case "uuid", "uuid4":
+			s.Format = "uuid"
+		case "ip", "ipv4":
+			s.Format = "ipv4"
+		case "ipv6":
+			s.Format = "ipv6"
+		case "datetime":
+			s.Format = "date-time"
+		case "alphanum":
+			s.Pattern = "^[a-zA-Z0-9]*$"
+		case "numeric":
+			s.Pattern = "^[-+]?[0-9]+(\\.[0-9]+)?$"
+		}
+	}
+}
+
+func setBound(s *Schema, param string, lower, exclusive bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch s.Type {
+	case "string":
+		v := int(n)
+		if lower {
+			s.MinLength = &v
+		} else {
+			s.MaxLength = &v
+		}
+	case "array":
+		v := int(n)
+		if lower {
+			s.MinItems = &v
+		} else {
+			s.MaxItems = &v
+		}
+	case "integer", "number":
+		switch {
+		case lower && exclusive:
+			s.ExclusiveMinimum = &n
+		case lower:
+			s.Minimum = &n
+		case exclusive:
+			s.ExclusiveMaximum = &n
+		default:
+			s.Maximum = &n
+		}
+	}
+}
+
+// parseTagValue 按 schema 类型解析 tag 中的值
+func parseTagValue(s *Schema, v string) any {
+	switch s.Type {
+	case "integer":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return v
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	errors "github.com/ergoapi/util/exerror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type createMemberReq struct {
+	OrgID     int64  `uri:"org" binding:"required"`
+	DryRun    bool   `form:"dry_run"`
+	RequestID string `header:"X-Request-Id"`
+	Name      string `json:"name" binding:"required,min=2,max=32" doc:"用户名"`
+	Role      string `json:"role" binding:"omitempty,oneof=admin member"`
+	Age       int    `json:"age" binding:"gte=0,lte=150"`
+	Email     string `json:"email,omitempty" binding:"omitempty,email"`
+}
+
+type member struct {
+	ID        int64     `json:"id"`
+	OrgID     int64     `json:"org_id"`
+	Name      string    `json:"name"`
+	RequestID string    `json:"request_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newOpenAPITestEngine() (*gin.Engine, *OpenAPI) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	api := NewOpenAPI(OpenAPIInfo{Title: "demo", Version: "v1"})
+	g := r.Group("/api")
+	Route(api, g, http.MethodPost, "/orgs/:org/members", func(c *gin.Context, req *createMemberReq) (*member, error) {
+		if req.Name == "taken" {
+			return nil, &errors.ErgoError{Message: "成员已存在", Code: http.StatusConflict}
+		}
+		return &member{ID: 1, OrgID: req.OrgID, Name: req.Name, RequestID: req.RequestID}, nil
+	}, WithSummary("创建成员"), WithTags("member"), WithBearerAuth())
+	Route(api, g, http.MethodGet, "/members", func(c *gin.Context, _ *struct{}) (*Page[member], error) {
+		q, err := ParseListQuery(c, &ListOptions{SortFields: []string{"created"}})
+		if err != nil {
+			return nil, err
+		}
+		return NewPage(c, q, []member{{ID: 1}}, 1), nil
+	}, WithListQuery(&ListOptions{SortFields: []string{"created"}, FilterFields: []string{"role"}}))
+	api.Serve(r, "/docs", OpenAPIUIRedoc)
+	return r, api
+}
+
+func TestRouteBindsAndResponds(t *testing.T) {
+	r, _ := newOpenAPITestEngine()
+	do := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/orgs/7/members?dry_run=true", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Request-Id", "req-1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(`{"name":"alice","age":20}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var ok struct {
+		Data member `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ok))
+	assert.Equal(t, int64(7), ok.Data.OrgID)
+	assert.Equal(t, "req-1", ok.Data.RequestID)
+
+	w = do(`{"name":"a","role":"owner"}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var bad struct {
+		Data ValidationErrors `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bad))
+	assert.Len(t, bad.Data, 2)
+
+	// body、query、header 中的同名字段不能覆盖 path 参数
+	req := httptest.NewRequest(http.MethodPost, "/api/orgs/7/members?OrgID=998", strings.NewReader(`{"name":"alice","OrgID":999,"RequestID":"evil"}`))
+	req.Header.Set("OrgID", "997")
+	req.Header.Set("X-Request-Id", "req-2")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ok))
+	assert.Equal(t, int64(7), ok.Data.OrgID)
+	assert.Equal(t, "req-2", ok.Data.RequestID)
+
+	assert.Equal(t, http.StatusBadRequest, do(`{`).Code)
+	assert.Equal(t, http.StatusConflict, do(`{"name":"taken"}`).Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/members?sort=name", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	r, _ := newOpenAPITestEngine()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var doc OpenAPIDocument
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "3.1.0", doc.OpenAPI)
+
+	post := doc.Paths["/api/orgs/{org}/members"]["post"]
+	require.NotNil(t, post)
+	assert.Equal(t, "创建成员", post.Summary)
+	assert.Equal(t, "post_api_orgs_org_members", post.OperationID)
+	params := map[string]Parameter{}
+	for _, p := range post.Parameters {
+		params[p.In+":"+p.Name] = p
+	}
+	assert.True(t, params["path:org"].Required)
+	assert.Equal(t, "boolean", params["query:dry_run"].Schema.Type)
+	assert.Contains(t, params, "header:X-Request-Id")
+	assert.NotEmpty(t, post.Security)
+	assert.Contains(t, post.Responses, "401")
+
+	body := post.RequestBody.Content["application/json"].Schema
+	assert.Equal(t, "#/components/schemas/createMemberReq", body.Ref)
+	reqSchema := doc.Components.Schemas["createMemberReq"]
+	require.NotNil(t, reqSchema)
+	assert.Equal(t, []string{"name"}, reqSchema.Required)
+	assert.NotContains(t, reqSchema.Properties, "OrgID", "path params are not part of the body")
+	name := reqSchema.Properties["name"]
+	assert.Equal(t, 2, *name.MinLength)
+	assert.Equal(t, 32, *name.MaxLength)
+	assert.Equal(t, "用户名", name.Description)
+	assert.Equal(t, []any{"admin", "member"}, reqSchema.Properties["role"].Enum)
+	assert.Equal(t, 150.0, *reqSchema.Properties["age"].Maximum)
+	assert.Equal(t, "email", reqSchema.Properties["email"].Format)
+
+	envelope := post.Responses["200"].Content["application/json"].Schema
+	assert.Equal(t, "#/components/schemas/member", envelope.Properties["data"].Ref)
+	assert.Contains(t, envelope.Properties, "traceId")
+	assert.Equal(t, "date-time", doc.Components.Schemas["member"].Properties["created_at"].Format)
+	assert.Equal(t, "array", post.Responses["400"].Content["application/json"].Schema.Properties["data"].Type)
+
+	list := doc.Paths["/api/members"]["get"]
+	require.NotNil(t, list)
+	assert.Nil(t, list.RequestBody)
+	var names []string
+	for _, p := range list.Parameters {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"page", "page_size", "sort", "filter[role]"}, names)
+	assert.Contains(t, doc.Components.Schemas, "Page_member")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `spec-url="/docs/openapi.json"`)
+}
+
+// URL 与 net/url.URL 同名
+type URL struct {
+	Link string `json:"link"`
+}
+
+func TestOpenAPISchemaNameCollision(t *testing.T) {
+	reg := newSchemaRegistry()
+	local := reg.schema(reflect.TypeOf(URL{}))
+	std := reg.schema(reflect.TypeOf(url.URL{}))
+	assert.Equal(t, "#/components/schemas/URL", local.Ref)
+	assert.Equal(t, "#/components/schemas/URL_url", std.Ref)
+	assert.Equal(t, local.Ref, reg.schema(reflect.TypeOf(&URL{})).Ref)
+	assert.Contains(t, reg.schemas["URL"].Properties, "link")
+	assert.Contains(t, reg.schemas["URL_url"].Properties, "Host")
+
+	// 泛型参数去掉包路径后同名
+	a := reg.schema(reflect.TypeOf(Page[member]{}))
+	b := reg.schema(reflect.TypeOf(Page[URL]{}))
+	c := reg.schema(reflect.TypeOf(Page[url.URL]{}))
+	assert.Equal(t, "#/components/schemas/Page_member", a.Ref)
+	assert.Equal(t, "#/components/schemas/Page_URL", b.Ref)
+	assert.Equal(t, "#/components/schemas/Page_URL_exgin", c.Ref)
+}
+
+func TestOpenAPIAssetBaseURL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	api := NewOpenAPI(OpenAPIInfo{Title: "demo", Version: "v1"})
+	api.Serve(r, "/swagger", OpenAPIUISwagger)
+	get := func() string {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger", nil))
+		return w.Body.String()
+	}
+	assert.Contains(t, get(), DefaultOpenAPIAssetBaseURL+"/swagger-ui-dist@5/swagger-ui-bundle.js")
+
+	api.SetAssetBaseURL("/static/npm/")
+	page := get()
+	assert.Contains(t, page, `src="/static/npm/swagger-ui-dist@5/swagger-ui-bundle.js"`)
+	assert.NotContains(t, page, "cdn.jsdelivr.net")
+}
@@ -0,0 +1,312 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// ErrInvalidCursor 游标被篡改或格式错误
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// BindQuery 将 path 参数(uri tag)、query 参数(form tag)和请求头(header tag)绑定到 T 并执行 binding 校验
+// 默认值使用 gin 的 form tag 语法, 例如 `form:"page_size,default=20" binding:"max=100"`
+// 校验失败时返回 ValidationErrors
+func BindQuery[T any](c *gin.Context) (*T, error) {
+	setupValidator()
+	v := new(T)
+	if err := mapRequestParams(c, v); err != nil {
+		return nil, bindError(c, err)
+	}
+	if err := binding.Validator.ValidateStruct(v); err != nil {
+		return nil, bindError(c, err)
+	}
+	return v, nil
+}
+
+// SortField 排序字段, sort=-created 表示按 created 倒序
+type SortField struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+// ListOptions ParseListQuery 配置
+type ListOptions struct {
+	// DefaultPageSize 默认每页条数, 默认 20
+	DefaultPageSize int
+	// MaxPageSize 每页最大条数, 默认 100
+	MaxPageSize int
+	// SortFields 允许排序的字段, 为空时不允许 sort 参数
+	SortFields []string
+	// DefaultSort 未传 sort 时使用, 例如 "-created"
+	DefaultSort string
+	// FilterFields 允许的 filter[field] 字段, 为空时不允许 filter 参数
+	FilterFields []string
+	// CursorSecret 游标签名密钥, 为空时不支持游标分页
+	CursorSecret []byte
+}
+
+func (o *ListOptions) setDefaults() {
+	if o.DefaultPageSize <= 0 {
+		o.DefaultPageSize = 20
+	}
+	if o.MaxPageSize <= 0 {
+		o.MaxPageSize = 100
+	}
+}
+
+// ListQuery 列表查询参数
+type ListQuery struct {
+	Page     int
+	PageSize int
+	// Cursor 游标原文, 使用 DecodeCursor 解析, 不为空时忽略 Page
+	Cursor  string
+	Sort    []SortField
+	Filters map[string]string
+
+	secret []byte
+}
+
+// Offset 偏移分页的 offset
+func (q *ListQuery) Offset() int {
+	return (q.Page - 1) * q.PageSize
+}
+
+// DecodeCursor 校验游标签名并解析到 v
+func (q *ListQuery) DecodeCursor(v any) error {
+	return DecodeCursor(q.secret, q.Cursor, v)
+}
+
+// EncodeCursor 使用 ListOptions.CursorSecret 生成下一页游标
+func (q *ListQuery) EncodeCursor(v any) (string, error) {
+	return EncodeCursor(q.secret, v)
+}
+
+// ParseListQuery 解析 page/page_size/cursor/sort/filter[field] 参数
+// 参数不合法或字段不在白名单时返回 ValidationErrors
+func ParseListQuery(c *gin.Context, opts *ListOptions) (*ListQuery, error) {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+	opts.setDefaults()
+	query := c.Request.URL.Query()
+	q := &ListQuery{Page: 1, PageSize: opts.DefaultPageSize, Filters: map[string]string{}, secret: opts.CursorSecret}
+	var verrs ValidationErrors
+
+	if raw := query.Get("page_size"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < 1 || size > opts.MaxPageSize {
+			verrs = append(verrs, FieldError{Field: "page_size", Tag: "max", Param: strconv.Itoa(opts.MaxPageSize),
+				Message: fmt.Sprintf("page_size 必须在1到%d之间", opts.MaxPageSize)})
+		} else {
+			q.PageSize = size
+		}
+	}
+	if raw := query.Get("page"); raw != "" {
+		// 限制 page 上限, 保证 Offset 计算 (page-1)*page_size 不溢出
+		maxPage := math.MaxInt/q.PageSize + 1
+		page, err := strconv.Atoi(raw)
+		switch {
+		case err != nil || page < 1:
+			verrs = append(verrs, FieldError{Field: "page", Tag: "min", Param: "1", Message: "page 必须为大于等于1的整数"})
+		case page > maxPage:
+			verrs = append(verrs, FieldError{Field: "page", Tag: "max", Param: strconv.Itoa(maxPage),
+				Message: fmt.Sprintf("page 不能大于%d", maxPage)})
+		default:
+			q.Page = page
+		}
+	}
+	if raw := query.Get("cursor"); raw != "" {
+		if len(opts.CursorSecret) == 0 || DecodeCursor(opts.CursorSecret, raw, nil) != nil {
+			verrs = append(verrs, FieldError{Field: "cursor", Tag: "cursor", Message: "cursor 不合法"})
+		} else {
+			q.Cursor = raw
+		}
+	}
+
+	rawSort := query.Get("sort")
+	if rawSort == "" {
+		rawSort = opts.DefaultSort
+	}
+	sorts, err := ParseSort(rawSort, opts.SortFields...)
+	if err != nil {
+		verrs = append(verrs, FieldError{Field: "sort", Tag: "oneof", Param: strings.Join(opts.SortFields, " "), Message: err.Error()})
+	}
+	q.Sort = sorts
+
+	for key, values := range query {
+		field, ok := filterField(key)
+		if !ok {
+			continue
+		}
+		if !slices.Contains(opts.FilterFields, field) {
+			verrs = append(verrs, FieldError{Field: key, Tag: "oneof", Param: strings.Join(opts.FilterFields, " "),
+				Message: fmt.Sprintf("不支持按 %s 过滤", field)})
+			continue
+		}
+		q.Filters[field] = values[0]
+	}
+	if len(verrs) > 0 {
+		return nil, verrs
+	}
+	return q, nil
+}
+
+// filterField 解析 filter[field] 形式的参数名
+func filterField(key string) (string, bool) {
+	if !strings.HasPrefix(key, "filter[") || !strings.HasSuffix(key, "]") {
+		return "", false
+	}
+	return key[len("filter[") : len(key)-1], true
+}
+
+// ParseSort 解析 "-created,name" 形式的排序参数, allowed 为空时任何字段都不允许
+func ParseSort(raw string, allowed ...string) ([]SortField, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var sorts []SortField
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		sf := SortField{Field: part}
+		switch part[0] {
+		case '-':
+			sf.Field, sf.Desc = part[1:], true
+		case '+':
+			sf.Field = part[1:]
+		}
+		if !slices.Contains(allowed, sf.Field) {
+			return nil, errors.Newf("不支持按 %s 排序", sf.Field)
+		}
+		sorts = append(sorts, sf)
+	}
+	return sorts, nil
+}
+
+// EncodeCursor 将 v 编码为带 HMAC 签名的不透明游标
+func EncodeCursor(secret []byte, v any) (string, error) {
+	if len(secret) == 0 {
+		return "", errors.New("cursor secret is empty")
+	}
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(cursorMAC(secret, payload)), nil
+}
+
+// DecodeCursor 校验游标签名并解析到 v, v 为 nil 时只校验
+func DecodeCursor(secret []byte, cursor string, v any) error {
+	enc := base64.RawURLEncoding
+	rawPayload, rawSig, ok := strings.Cut(cursor, ".")
+	if !ok || len(secret) == 0 {
+		return ErrInvalidCursor
+	}
+	payload, err := enc.DecodeString(rawPayload)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	sig, err := enc.DecodeString(rawSig)
+	if err != nil || !hmac.Equal(sig, cursorMAC(secret, payload)) {
+		return ErrInvalidCursor
+	}
+	if v == nil {
+		return nil
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return errors.Mark(err, ErrInvalidCursor)
+	}
+	return nil
+}
+
+func cursorMAC(secret, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// PageLinks 分页链接, 基于当前请求 URL 生成
+type PageLinks struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// Page 分页响应
+type Page[T any] struct {
+	Items      []T       `json:"items"`
+	Total      int64     `json:"total"`
+	Page       int       `json:"page,omitempty"`
+	PageSize   int       `json:"page_size"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	Links      PageLinks `json:"links"`
+}
+
+// NewPage 构造偏移分页响应, total 小于 0 表示总数未知
+func NewPage[T any](c *gin.Context, q *ListQuery, items []T, total int64) *Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	p := &Page[T]{Items: items, Total: total, Page: q.Page, PageSize: q.PageSize}
+	p.Links.Self = c.Request.URL.RequestURI()
+	hasNext := int64(q.Page*q.PageSize) < total
+	if total < 0 {
+		hasNext = len(items) == q.PageSize
+	}
+	if hasNext {
+		p.Links.Next = pageURL(c, url.Values{"page": {strconv.Itoa(q.Page + 1)}})
+	}
+	if q.Page > 1 {
+		p.Links.Prev = pageURL(c, url.Values{"page": {strconv.Itoa(q.Page - 1)}})
+	}
+	return p
+}
+
+// NewCursorPage 构造游标分页响应, next 为空表示没有下一页
+func NewCursorPage[T any](c *gin.Context, q *ListQuery, items []T, total int64, next string) *Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	p := &Page[T]{Items: items, Total: total, PageSize: q.PageSize, NextCursor: next}
+	p.Links.Self = c.Request.URL.RequestURI()
+	if next != "" {
+		p.Links.Next = pageURL(c, url.Values{"cursor": {next}, "page": nil})
+	}
+	return p
+}
+
+// pageURL 基于当前请求替换分页参数, 值为 nil 表示删除该参数
+func pageURL(c *gin.Context, set url.Values) string {
+	u := *c.Request.URL
+	query := u.Query()
+	for k, v := range set {
+		if v == nil {
+			query.Del(k)
+			continue
+		}
+		query[k] = v
+	}
+	u.RawQuery = query.Encode()
+	return u.RequestURI()
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type listUsersQuery struct {
+	OrgID    int64  `uri:"org" binding:"required"`
+	Keyword  string `form:"q"`
+	PageSize int    `form:"page_size,default=20" binding:"min=1,max=100"`
+}
+
+func TestBindQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var got *listUsersQuery
+	r := gin.New()
+	r.GET("/orgs/:org/users", func(c *gin.Context) {
+		q, err := BindQuery[listUsersQuery](c)
+		if err != nil {
+			ErrorResponse(c, http.StatusBadRequest, err)
+			return
+		}
+		got = q
+		SucessResponse(c, nil)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orgs/7/users?q=bob", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, &listUsersQuery{OrgID: 7, Keyword: "bob", PageSize: 20}, got)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orgs/7/users?page_size=500", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var resp struct {
+		Data ValidationErrors `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, "page_size", resp.Data[0].Field)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orgs/x/users", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// 无 form tag 的字段不能被同名 query 参数覆盖, path 参数优先
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orgs/7/users?OrgID=999&Keyword=x&q=bob", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, &listUsersQuery{OrgID: 7, Keyword: "bob", PageSize: 20}, got)
+}
+
+func TestParseListQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	opts := &ListOptions{SortFields: []string{"created", "name"}, FilterFields: []string{"status"}, CursorSecret: []byte("s3cret")}
+	parse := func(target string) (*ListQuery, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+		return ParseListQuery(c, opts)
+	}
+
+	q, err := parse("/users?page=3&page_size=10&sort=-created,name&filter[status]=active")
+	require.NoError(t, err)
+	assert.Equal(t, 20, q.Offset())
+	assert.Equal(t, []SortField{{Field: "created", Desc: true}, {Field: "name"}}, q.Sort)
+	assert.Equal(t, map[string]string{"status": "active"}, q.Filters)
+
+	_, err = parse("/users?sort=password&filter[role]=admin&page=0")
+	var verrs ValidationErrors
+	require.ErrorAs(t, err, &verrs)
+	fields := []string{}
+	for _, fe := range verrs {
+		fields = append(fields, fe.Field)
+	}
+	assert.ElementsMatch(t, []string{"page", "sort", "filter[role]"}, fields)
+
+	maxPage := math.MaxInt/10 + 1
+	q, err = parse("/users?page_size=10&page=" + strconv.Itoa(maxPage))
+	require.NoError(t, err)
+	assert.Positive(t, q.Offset())
+	_, err = parse("/users?page_size=10&page=" + strconv.Itoa(maxPage+1))
+	require.ErrorAs(t, err, &verrs)
+	require.Len(t, verrs, 1)
+	assert.Equal(t, "page", verrs[0].Field)
+	assert.Equal(t, "max", verrs[0].Tag)
+
+	cursor, err := EncodeCursor(opts.CursorSecret, map[string]int64{"id": 42})
+	require.NoError(t, err)
+	q, err = parse("/users?cursor=" + cursor)
+	require.NoError(t, err)
+	var pos map[string]int64
+	require.NoError(t, q.DecodeCursor(&pos))
+	assert.Equal(t, int64(42), pos["id"])
+
+	_, err = parse("/users?cursor=" + cursor + "x")
+	assert.Error(t, err, "tampered cursor")
+	assert.ErrorIs(t, DecodeCursor([]byte("other"), cursor, &pos), ErrInvalidCursor)
+}
+
+func TestPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/users?page=2&page_size=2&sort=name", nil)
+	q, err := ParseListQuery(c, &ListOptions{SortFields: []string{"name"}})
+	require.NoError(t, err)
+
+	p := NewPage(c, q, []string{"c", "d"}, 5)
+	assert.Equal(t, "/users?page=3&page_size=2&sort=name", p.Links.Next)
+	assert.Equal(t, "/users?page=1&page_size=2&sort=name", p.Links.Prev)
+
+	p = NewPage[string](c, q, nil, 4)
+	assert.Empty(t, p.Links.Next)
+	assert.NotNil(t, p.Items)
+
+	cp := NewCursorPage(c, q, []string{"e"}, -1, "abc")
+	assert.Equal(t, "abc", cp.NextCursor)
+	assert.Equal(t, "/users?cursor=abc&page_size=2&sort=name", cp.Links.Next)
+}
@@ -7,7 +7,7 @@
 package exgin
 
 import (
-	"fmt"
+	stderrors "errors"
 	"time"
 
 	errors "github.com/ergoapi/util/exerror"
@@ -45,13 +45,22 @@ func SucessResponse(c *gin.Context, data any) {
 
 func ErrorResponse(c *gin.Context, httpcode int, err error) {
 	traceID := getTraceID(c)
-	c.JSON(httpcode, newResponse(httpcode, traceID, nil, err.Error()))
+	c.JSON(httpcode, newResponse(httpcode, traceID, errorData(err), err.Error()))
 }
 
 // ErrorResponse2xx 处理错误响应, 状态码为200
 func ErrorResponse2xx(c *gin.Context, code int, err error) {
 	traceID := getTraceID(c)
-	c.JSON(200, newResponse(code, traceID, nil, err.Error()))
+	c.JSON(200, newResponse(code, traceID, errorData(err), err.Error()))
+}
+
+// errorData 参数校验错误时返回字段错误列表作为 data
+func errorData(err error) any {
+	var verrs ValidationErrors
+	if stderrors.As(err, &verrs) {
+		return verrs
+	}
+	return nil
 }
 
 // GinsData 处理通用数据响应
@@ -85,18 +94,27 @@ func GinsAbort200(c *gin.Context, code int, msg string) {
 }
 
 // Bind 绑定JSON请求体
+// 校验失败时 panic ValidationErrors, 由 ExRecovery 返回字段级错误列表
 func Bind(c *gin.Context, ptr any) {
-	err := c.ShouldBindJSON(ptr)
-	if err != nil {
-		errors.Bomb("参数不合法: %v", err)
+	if err := BindWithErr(c, ptr); err != nil {
+		var verrs ValidationErrors
+		if stderrors.As(err, &verrs) {
+			panic(verrs)
+		}
+		if isBodyTooLarge(err) {
+			errors.BombCode(413, "%v", err)
+		}
+		errors.Bomb("%v", err)
 	}
 }
 
 // BindWithErr 绑定JSON请求体并返回错误
+// 校验失败时返回 ValidationErrors, 字段名取 json tag, 提示按请求语言翻译
 func BindWithErr(c *gin.Context, ptr any) error {
+	setupValidator()
 	err := c.ShouldBindJSON(ptr)
 	if err != nil {
-		return fmt.Errorf("参数不合法: %v", err)
+		return bindError(c, err)
 	}
 	return nil
 }
@@ -0,0 +1,343 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ergoapi/util/common"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cspNonceKey         = "ex-csp-nonce"
+	cspNoncePlaceholder = "{nonce}"
+)
+
+// SecureOptions 安全响应头配置, 空字段表示不设置对应响应头
+type SecureOptions struct {
+	// ContentSecurityPolicy 其中的 {nonce} 会替换为每个请求独立的随机值, 例如 "script-src 'self' 'nonce-{nonce}'"
+	ContentSecurityPolicy string
+	// CSPReportOnly 使用 Content-Security-Policy-Report-Only, 只上报不拦截
+	CSPReportOnly bool
+	// CSPReportURI 违规上报地址, 追加 report-uri 指令, 可配合 CSPReportHandler 使用
+	CSPReportURI string
+
+	// HSTSMaxAge 大于 0 时对 HTTPS 请求设置 Strict-Transport-Security
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+
+	ContentTypeNosniff        bool
+	FrameOptions              string
+	ReferrerPolicy            string
+	PermissionsPolicy         string
+	CrossOriginOpenerPolicy   string
+	CrossOriginEmbedderPolicy string
+	CrossOriginResourcePolicy string
+}
+
+// DefaultSecureOptions 适用于 JSON API 的默认安全头
+func DefaultSecureOptions() *SecureOptions {
+	return &SecureOptions{
+		ContentSecurityPolicy:     "default-src 'none'; frame-ancestors 'none'",
+		HSTSMaxAge:                365 * 24 * time.Hour,
+		HSTSIncludeSubdomains:     true,
+		ContentTypeNosniff:        true,
+		FrameOptions:              "DENY",
+		ReferrerPolicy:            "strict-origin-when-cross-origin",
+		PermissionsPolicy:         "camera=(), microphone=(), geolocation=()",
+		CrossOriginOpenerPolicy:   "same-origin",
+		CrossOriginResourcePolicy: "same-origin",
+	}
+}
+
+// Secure 设置安全响应头, opts 为 nil 时使用 DefaultSecureOptions
+func Secure(opts *SecureOptions) gin.HandlerFunc {
+	if opts == nil {
+		opts = DefaultSecureOptions()
+	}
+	csp := opts.ContentSecurityPolicy
+	if csp != "" && opts.CSPReportURI != "" {
+		csp = strings.TrimRight(strings.TrimSpace(csp), ";") + "; report-uri " + opts.CSPReportURI
+	}
+	cspHeader := "Content-Security-Policy"
+	if opts.CSPReportOnly {
+		cspHeader = "Content-Security-Policy-Report-Only"
+	}
+	hsts := ""
+	if opts.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", int64(opts.HSTSMaxAge.Seconds()))
+		if opts.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if opts.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+	static := map[string]string{
+		"X-Frame-Options":              opts.FrameOptions,
+		"Referrer-Policy":              opts.ReferrerPolicy,
+		"Permissions-Policy":           opts.PermissionsPolicy,
+		"Cross-Origin-Opener-Policy":   opts.CrossOriginOpenerPolicy,
+		"Cross-Origin-Embedder-Policy": opts.CrossOriginEmbedderPolicy,
+		"Cross-Origin-Resource-Policy": opts.CrossOriginResourcePolicy,
+	}
+	if opts.ContentTypeNosniff {
+		static["X-Content-Type-Options"] = "nosniff"
+	}
+
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		for k, v := range static {
+			if v != "" {
+				h.Set(k, v)
+			}
+		}
+		if hsts != "" && isHTTPS(c) {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		if csp != "" {
+			policy := csp
+			if strings.Contains(policy, cspNoncePlaceholder) {
+				nonce := newCSPNonce()
+				c.Set(cspNonceKey, nonce)
+				policy = strings.ReplaceAll(policy, cspNoncePlaceholder, nonce)
+			}
+			h.Set(cspHeader, policy)
+		}
+		c.Next()
+	}
+}
+
+func isHTTPS(c *gin.Context) bool {
+	return c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+}
+
+func newCSPNonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// CSPNonce 获取当前请求的 CSP nonce, 用于模板中的 <script nonce="...">
+func CSPNonce(c *gin.Context) string {
+	return c.GetString(cspNonceKey)
+}
+
+// CSPReport CSP 违规报告, 兼容 application/csp-report 和 Reporting API 两种格式
+type CSPReport struct {
+	DocumentURI        string `json:"document-uri"`
+	Referrer           string `json:"referrer"`
+	ViolatedDirective  string `json:"violated-directive"`
+	EffectiveDirective string `json:"effective-directive"`
+	OriginalPolicy     string `json:"original-policy"`
+	Disposition        string `json:"disposition"`
+	BlockedURI         string `json:"blocked-uri"`
+	SourceFile         string `json:"source-file"`
+	LineNumber         int    `json:"line-number"`
+	StatusCode         int    `json:"status-code"`
+}
+
+type reportingAPIReport struct {
+	Type string `json:"type"`
+	Body struct {
+		DocumentURL        string `json:"documentURL"`
+		Referrer           string `json:"referrer"`
+		EffectiveDirective string `json:"effectiveDirective"`
+		OriginalPolicy     string `json:"originalPolicy"`
+		Disposition        string `json:"disposition"`
+		BlockedURL         string `json:"blockedURL"`
+		SourceFile         string `json:"sourceFile"`
+		LineNumber         int    `json:"lineNumber"`
+		StatusCode         int    `json:"statusCode"`
+	} `json:"body"`
+}
+
+// CSPReportHandler 接收浏览器上报的 CSP 违规, fn 为 nil 时记录 warning 日志
+func CSPReportHandler(fn func(c *gin.Context, report CSPReport)) gin.HandlerFunc {
+	if fn == nil {
+		fn = func(c *gin.Context, r CSPReport) {
+			logrus.WithFields(logrus.Fields{
+				"Tag":       "csp",
+				"document":  r.DocumentURI,
+				"directive": r.EffectiveDirective,
+				"blocked":   r.BlockedURI,
+				"source":    fmt.Sprintf("%s:%d", r.SourceFile, r.LineNumber),
+				"client_ip": c.ClientIP(),
+			}).Warn("csp violation")
+		}
+	}
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, 64<<10))
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		reports, err := parseCSPReports(c.ContentType(), body)
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		for _, r := range reports {
+			fn(c, r)
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func parseCSPReports(contentType string, body []byte) ([]CSPReport, error) {
+	if contentType == "application/reports+json" {
+		var items []reportingAPIReport
+		if err := json.Unmarshal(body, &items); err != nil {
+			return nil, err
+		}
+		reports := make([]CSPReport, 0, len(items))
+		for _, it := range items {
+			if it.Type != "csp-violation" {
+				continue
+			}
+			reports = append(reports, CSPReport{
+				DocumentURI:        it.Body.DocumentURL,
+				Referrer:           it.Body.Referrer,
+				ViolatedDirective:  it.Body.EffectiveDirective,
+				EffectiveDirective: it.Body.EffectiveDirective,
+				OriginalPolicy:     it.Body.OriginalPolicy,
+				Disposition:        it.Body.Disposition,
+				BlockedURI:         it.Body.BlockedURL,
+				SourceFile:         it.Body.SourceFile,
+				LineNumber:         it.Body.LineNumber,
+				StatusCode:         it.Body.StatusCode,
+			})
+		}
+		return reports, nil
+	}
+	var legacy struct {
+		Report CSPReport `json:"csp-report"`
+	}
+	if err := json.Unmarshal(body, &legacy); err != nil {
+		return nil, err
+	}
+	return []CSPReport{legacy.Report}, nil
+}
+
+// BotRule 爬虫/扫描器拦截规则, 规则内所有非空条件同时满足才算命中, 均为正则表达式
+type BotRule struct {
+	Name      string
+	UserAgent string
+	Path      string
+	// Header 请求头名称, HeaderValue 为空时只要求该请求头存在
+	Header      string
+	HeaderValue string
+}
+
+type compiledBotRule struct {
+	name        string
+	ua          *regexp.Regexp
+	path        *regexp.Regexp
+	header      string
+	headerValue *regexp.Regexp
+}
+
+func (r *compiledBotRule) match(c *gin.Context) bool {
+	if r.ua != nil && !r.ua.MatchString(c.Request.UserAgent()) {
+		return false
+	}
+	if r.path != nil && !r.path.MatchString(c.Request.URL.Path) {
+		return false
+	}
+	if r.header != "" {
+		values, ok := c.Request.Header[http.CanonicalHeaderKey(r.header)]
+		if !ok {
+			return false
+		}
+		if r.headerValue != nil && !r.headerValue.MatchString(strings.Join(values, ",")) {
+			return false
+		}
+	}
+	return true
+}
+
+// DefaultBotRules 基于 common.FakeUA 的默认规则, 不区分大小写匹配 User-Agent
+// 空 User-Agent 不拦截, 需要时可追加 BotRule{Name: "empty-ua", UserAgent: "^$"}
+func DefaultBotRules() []BotRule {
+	quoted := make([]string, 0, len(common.FakeUA))
+	for _, ua := range common.FakeUA {
+		quoted = append(quoted, regexp.QuoteMeta(ua))
+	}
+	return []BotRule{
+		{Name: "fake-ua", UserAgent: "(?i)(" + strings.Join(quoted, "|") + ")"},
+	}
+}
+
+// NewBotBlocker 编译拦截规则, 任一规则命中即返回 403
+func NewBotBlocker(rules []BotRule) (gin.HandlerFunc, error) {
+	compiled := make([]*compiledBotRule, 0, len(rules))
+	for _, r := range rules {
+		cr := &compiledBotRule{name: r.Name, header: r.Header}
+		var err error
+		if cr.ua, err = compileOptional(r.UserAgent); err != nil {
+			return nil, errors.Wrapf(err, "bot rule %s: user agent", r.Name)
+		}
+		if cr.path, err = compileOptional(r.Path); err != nil {
+			return nil, errors.Wrapf(err, "bot rule %s: path", r.Name)
+		}
+		if cr.headerValue, err = compileOptional(r.HeaderValue); err != nil {
+			return nil, errors.Wrapf(err, "bot rule %s: header value", r.Name)
+		}
+		if cr.ua == nil && cr.path == nil && cr.header == "" {
+			return nil, errors.Newf("bot rule %s: no condition", r.Name)
+		}
+		compiled = append(compiled, cr)
+	}
+	return func(c *gin.Context) {
+		for _, r := range compiled {
+			if r.match(c) {
+				logrus.WithFields(logrus.Fields{
+					"Tag":       "exgin",
+					"rule":      r.name,
+					"ua":        c.Request.UserAgent(),
+					"path":      c.Request.URL.Path,
+					"client_ip": c.ClientIP(),
+				}).Debug("bot blocked")
+				GinsAbort(c, http.StatusForbidden, "forbidden")
+				return
+			}
+		}
+		c.Next()
+	}, nil
+}
+
+// BotBlock 与 NewBotBlocker 相同, 规则不合法时 panic, 未传规则时使用 DefaultBotRules
+func BotBlock(rules ...BotRule) gin.HandlerFunc {
+	if len(rules) == 0 {
+		rules = DefaultBotRules()
+	}
+	h, err := NewBotBlocker(rules)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+func compileOptional(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile(expr)
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	opts := DefaultSecureOptions()
+	opts.ContentSecurityPolicy = "script-src 'self' 'nonce-{nonce}'"
+	opts.CSPReportURI = "/csp-report"
+	opts.HSTSMaxAge = time.Hour
+
+	var nonce string
+	r := gin.New()
+	r.Use(Secure(opts))
+	r.GET("/", func(c *gin.Context) {
+		nonce = CSPNonce(c)
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NotEmpty(t, nonce)
+	assert.Equal(t, "script-src 'self' 'nonce-"+nonce+"'; report-uri /csp-report", w.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"), "hsts only over https")
+	assert.Empty(t, w.Header().Get("Cross-Origin-Embedder-Policy"))
+
+	first := nonce
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.NotEqual(t, first, nonce, "nonce is per request")
+	assert.Equal(t, "max-age=3600; includeSubDomains", w.Header().Get("Strict-Transport-Security"))
+
+	ro := gin.New()
+	ro.Use(Secure(&SecureOptions{ContentSecurityPolicy: "default-src 'self'", CSPReportOnly: true}))
+	ro.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	w = httptest.NewRecorder()
+	ro.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Empty(t, w.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "default-src 'self'", w.Header().Get("Content-Security-Policy-Report-Only"))
+}
+
+func TestCSPReportHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var got []CSPReport
+	r := gin.New()
+	r.POST("/csp-report", CSPReportHandler(func(_ *gin.Context, report CSPReport) {
+		got = append(got, report)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/csp-report",
+		strings.NewReader(`{"csp-report":{"document-uri":"https://a.com/","effective-directive":"script-src","blocked-uri":"inline"}}`))
+	req.Header.Set("Content-Type", "application/csp-report")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/csp-report",
+		strings.NewReader(`[{"type":"csp-violation","body":{"documentURL":"https://b.com/","effectiveDirective":"img-src","blockedURL":"https://evil.com/x.png"}},{"type":"deprecation"}]`))
+	req.Header.Set("Content-Type", "application/reports+json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	require.Len(t, got, 2)
+	assert.Equal(t, "https://a.com/", got[0].DocumentURI)
+	assert.Equal(t, "inline", got[0].BlockedURI)
+	assert.Equal(t, "img-src", got[1].EffectiveDirective)
+	assert.Equal(t, "https://evil.com/x.png", got[1].BlockedURI)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/csp-report", strings.NewReader("not json")))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestBotBlock(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rules := append(DefaultBotRules(),
+		BotRule{Name: "wp-scan", Path: `^/wp-(admin|login)`},
+		BotRule{Name: "scanner-header", Header: "X-Scanner"},
+		BotRule{Name: "curl-admin", UserAgent: `^curl/`, Path: `^/admin`},
+	)
+	r := gin.New()
+	r.Use(BotBlock(rules...))
+	r.GET("/*path", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	cases := []struct {
+		ua, path, header string
+		code             int
+	}{
+		{"Mozilla/5.0", "/", "", http.StatusOK},
+		// 默认规则不拦截空 User-Agent
+		{"", "/", "", http.StatusOK},
+		{"python-requests/2.31", "/", "", http.StatusForbidden},
+		{"Masscan/1.3", "/", "", http.StatusForbidden},
+		{"Mozilla/5.0", "/wp-login.php", "", http.StatusForbidden},
+		{"Mozilla/5.0", "/", "X-Scanner", http.StatusForbidden},
+		{"curl/8.0", "/", "", http.StatusOK},
+		{"curl/8.0", "/admin", "", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set("User-Agent", tc.ua)
+		if tc.header != "" {
+			req.Header.Set(tc.header, "1")
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, tc.code, w.Code, "ua=%q path=%q", tc.ua, tc.path)
+	}
+
+	_, err := NewBotBlocker([]BotRule{{Name: "bad", UserAgent: "("}})
+	assert.Error(t, err)
+	_, err = NewBotBlocker([]BotRule{{Name: "empty"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ergoapi/util/cache"
+	"github.com/ergoapi/util/exhttp"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const signKeyIDKey = "ex-sign-key-id"
+
+// SignatureOptions SignatureAuth 配置
+type SignatureOptions struct {
+	// SecretFunc 根据 key id 查找密钥, 返回错误表示 key 不存在或已停用
+	SecretFunc func(keyID string) (string, error)
+	// MaxSkew 允许的时钟偏差, 默认 5m
+	MaxSkew time.Duration
+	// Cache 记录已使用的 nonce 防重放, 默认 cache.Instance, 均为 nil 时 SignatureAuth panic
+	Cache cache.Cache
+	// MaxBodySize 参与签名的请求体上限, 默认 10MB
+	MaxBodySize int64
+}
+
+// SignatureAuth 校验 exhttp.Signer 签名的请求, 签名覆盖 method、path、排序后的 query、body 哈希和时间戳
+// 校验失败返回 401, nonce 缓存故障时返回 503, 通过后可用 GetSignatureKeyID 获取调用方 key id
+func SignatureAuth(opts *SignatureOptions) gin.HandlerFunc {
+	if opts == nil || opts.SecretFunc == nil {
+		panic("exgin: SignatureAuth requires a SecretFunc")
+	}
+	o := *opts
+	if o.Cache == nil {
+		o.Cache = cache.Instance
+	}
+	if o.Cache == nil {
+		panic("exgin: SignatureAuth requires a nonce Cache")
+	}
+	if o.MaxSkew <= 0 {
+		o.MaxSkew = 5 * time.Minute
+	}
+	if o.MaxBodySize <= 0 {
+		o.MaxBodySize = 10 << 20
+	}
+	return func(c *gin.Context) {
+		keyID := c.GetHeader(exhttp.HeaderSignKeyID)
+		timestamp := c.GetHeader(exhttp.HeaderSignTimestamp)
+		nonce := c.GetHeader(exhttp.HeaderSignNonce)
+		signature := c.GetHeader(exhttp.HeaderSignature)
+		if keyID == "" || timestamp == "" || nonce == "" || signature == "" {
+			GinsAbort(c, http.StatusUnauthorized, "缺少签名")
+			return
+		}
+		ts, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			GinsAbort(c, http.StatusUnauthorized, "签名时间戳不合法")
+			return
+		}
+		if skew := time.Since(time.Unix(ts, 0)); skew > o.MaxSkew || skew < -o.MaxSkew {
+			GinsAbort(c, http.StatusUnauthorized, "签名已过期")
+			return
+		}
+		secret, err := o.SecretFunc(keyID)
+		if err != nil || secret == "" {
+			GinsAbort(c, http.StatusUnauthorized, "签名 key 不存在")
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			body, err = io.ReadAll(io.LimitReader(c.Request.Body, o.MaxBodySize+1))
+			if err != nil {
+				GinsAbort(c, http.StatusBadRequest, "读取请求体失败")
+				return
+			}
+			if int64(len(body)) > o.MaxBodySize {
+				GinsAbort(c, http.StatusRequestEntityTooLarge, "请求体过大")
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		sts := exhttp.StringToSign(c.Request.Method, c.Request.URL.EscapedPath(), c.Request.URL.Query(), body, timestamp, nonce)
+		if !hmac.Equal([]byte(exhttp.Sign(secret, sts)), []byte(signature)) {
+			GinsAbort(c, http.StatusUnauthorized, "签名不匹配")
+			return
+		}
+
+		// 注意: cache.Cache 没有原子 SetNX, 并发的相同 nonce 仍有极小的竞争窗口
+		nonceKey := "ex-sign-nonce:" + keyID + ":" + nonce
+		_, err = o.Cache.Get(c.Request.Context(), nonceKey)
+		if err == nil {
+			GinsAbort(c, http.StatusUnauthorized, "重复的请求")
+			return
+		}
+		if !errors.Is(err, cache.ErrNotFound) {
+			logrus.Warnf("signature: load nonce failed: %v", err)
+			GinsAbort(c, http.StatusServiceUnavailable, "签名校验暂不可用, 请稍后重试")
+			return
+		}
+		if err := o.Cache.Set(c.Request.Context(), nonceKey, "1", cache.WithExpiration(2*o.MaxSkew)); err != nil {
+			logrus.Warnf("signature: save nonce failed: %v", err)
+			GinsAbort(c, http.StatusServiceUnavailable, "签名校验暂不可用, 请稍后重试")
+			return
+		}
+
+		c.Set(signKeyIDKey, keyID)
+		c.Next()
+	}
+}
+
+// GetSignatureKeyID 获取通过签名校验的调用方 key id
+func GetSignatureKeyID(c *gin.Context) string {
+	return c.GetString(signKeyIDKey)
+}
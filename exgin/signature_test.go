@@ -0,0 +1,125 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/ergoapi/util/cache"
+	"github.com/ergoapi/util/exhttp"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(SignatureAuth(&SignatureOptions{
+		SecretFunc: func(keyID string) (string, error) {
+			if keyID == "partner" {
+				return "s3cret", nil
+			}
+			return "", errors.New("unknown key")
+		},
+		Cache: cache.NewGoCache(cache.WithExpiration(time.Minute)),
+	}))
+	r.POST("/hooks", func(c *gin.Context) {
+		var v map[string]any
+		Bind(c, &v)
+		SucessResponse(c, GetSignatureKeyID(c))
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	client := &http.Client{Transport: exhttp.NewSigner("partner", "s3cret").RoundTripper(nil)}
+	resp, err := client.Post(srv.URL+"/hooks?b=2&a=1&a=0", "application/json", strings.NewReader(`{"event":"push"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	signed := func(signer *exhttp.Signer, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/hooks", strings.NewReader(body))
+		require.NoError(t, signer.Sign(req))
+		return req
+	}
+	do := func(req *http.Request) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	replay := signed(exhttp.NewSigner("partner", "s3cret"), `{"a":1}`)
+	nonce := replay.Header.Get(exhttp.HeaderSignNonce)
+	assert.Equal(t, http.StatusOK, do(replay))
+	again := signed(exhttp.NewSigner("partner", "s3cret"), `{"a":1}`)
+	again.Header = replay.Header.Clone()
+	assert.Equal(t, nonce, again.Header.Get(exhttp.HeaderSignNonce))
+	assert.Equal(t, http.StatusUnauthorized, do(again), "nonce replay")
+
+	tampered := signed(exhttp.NewSigner("partner", "s3cret"), `{"a":1}`)
+	tampered.Body = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":2}`)).Body
+	assert.Equal(t, http.StatusUnauthorized, do(tampered), "body tampered")
+
+	assert.Equal(t, http.StatusUnauthorized, do(signed(exhttp.NewSigner("partner", "wrong"), `{}`)))
+	assert.Equal(t, http.StatusUnauthorized, do(signed(exhttp.NewSigner("other", "s3cret"), `{}`)))
+
+	stale := &exhttp.Signer{KeyID: "partner", Secret: "s3cret", Now: func() time.Time { return time.Now().Add(-time.Hour) }}
+	assert.Equal(t, http.StatusUnauthorized, do(signed(stale, `{}`)), "clock skew")
+
+	assert.Equal(t, http.StatusUnauthorized, do(httptest.NewRequest(http.MethodPost, "/hooks", nil)))
+}
+
+func TestSignatureAuthRequiresSecretFunc(t *testing.T) {
+	assert.PanicsWithValue(t, "exgin: SignatureAuth requires a SecretFunc", func() { SignatureAuth(nil) })
+	assert.PanicsWithValue(t, "exgin: SignatureAuth requires a SecretFunc", func() { SignatureAuth(&SignatureOptions{}) })
+
+	secret := func(string) (string, error) { return "s3cret", nil }
+	prev := cache.Instance
+	cache.Instance = nil
+	defer func() { cache.Instance = prev }()
+	assert.PanicsWithValue(t, "exgin: SignatureAuth requires a nonce Cache", func() {
+		SignatureAuth(&SignatureOptions{SecretFunc: secret})
+	})
+
+	// 默认值不写回调用方的配置
+	opts := &SignatureOptions{SecretFunc: secret, Cache: cache.NewGoCache()}
+	SignatureAuth(opts)
+	assert.Zero(t, opts.MaxSkew)
+	assert.Zero(t, opts.MaxBodySize)
+}
+
+func TestSignatureAuthNonceCacheFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &flakyCache{Cache: cache.NewGoCache()}
+	r := gin.New()
+	r.Use(SignatureAuth(&SignatureOptions{
+		SecretFunc: func(string) (string, error) { return "s3cret", nil },
+		Cache:      store,
+	}))
+	r.POST("/hooks", func(c *gin.Context) { SucessResponse(c, "ok") })
+	do := func() int {
+		req := httptest.NewRequest(http.MethodPost, "/hooks", strings.NewReader(`{}`))
+		require.NoError(t, exhttp.NewSigner("partner", "s3cret").Sign(req))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	store.getErr = errors.New("redis down")
+	assert.Equal(t, http.StatusServiceUnavailable, do())
+	store.getErr = nil
+	store.setErr = errors.New("redis down")
+	assert.Equal(t, http.StatusServiceUnavailable, do())
+	store.setErr = nil
+	assert.Equal(t, http.StatusOK, do())
+}
@@ -0,0 +1,357 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+const lastEventIDHeader = "Last-Event-ID"
+
+// ErrSSEClosed 客户端断开或订阅被关闭
+var ErrSSEClosed = errors.New("sse stream closed")
+
+// Event SSE 事件, Data 为非字符串时按 JSON 编码
+type Event struct {
+	ID    string
+	Event string
+	Data  any
+	// Retry 建议客户端的重连间隔
+	Retry time.Duration
+}
+
+// SSEOptions SSE 连接配置
+type SSEOptions struct {
+	// Heartbeat 心跳间隔, 默认 15s, 小于 0 表示关闭, 用于保持代理连接和及时发现断开
+	Heartbeat time.Duration
+	// Retry 建立连接时下发的重连间隔, 0 表示不下发
+	Retry time.Duration
+}
+
+func (o *SSEOptions) setDefaults() {
+	if o.Heartbeat == 0 {
+		o.Heartbeat = 15 * time.Second
+	}
+}
+
+// SSEStream 单个 SSE 连接
+type SSEStream struct {
+	c  *gin.Context
+	mu sync.Mutex
+}
+
+// NewSSEStream 设置 SSE 响应头并返回连接, retry 大于 0 时下发重连间隔
+func NewSSEStream(c *gin.Context, retry time.Duration) *SSEStream {
+	h := c.Writer.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	// 关闭 nginx 缓冲
+	h.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	s := &SSEStream{c: c}
+	if retry > 0 {
+		_ = s.write(func(w io.Writer) error {
+			_, err := io.WriteString(w, "retry:"+strconv.FormatInt(retry.Milliseconds(), 10)+"\n\n")
+			return err
+		})
+	} else {
+		c.Writer.WriteHeaderNow()
+		c.Writer.Flush()
+	}
+	return s
+}
+
+// LastEventID 客户端重连时携带的最后事件ID, 兼容不支持自定义头的客户端使用 lastEventId 参数
+func (s *SSEStream) LastEventID() string {
+	return lastEventID(s.c)
+}
+
+func lastEventID(c *gin.Context) string {
+	if id := c.GetHeader(lastEventIDHeader); id != "" {
+		return id
+	}
+	return c.Query("lastEventId")
+}
+
+// Done 客户端断开时关闭
+func (s *SSEStream) Done() <-chan struct{} {
+	return s.c.Request.Context().Done()
+}
+
+// Send 发送事件并立即 flush, 客户端已断开时返回 ErrSSEClosed
+func (s *SSEStream) Send(ev Event) error {
+	return s.write(func(w io.Writer) error {
+		return sse.Encode(w, sse.Event{
+			Id:    ev.ID,
+			Event: ev.Event,
+			Data:  ev.Data,
+			Retry: uint(ev.Retry.Milliseconds()),
+		})
+	})
+}
+
+// Heartbeat 发送注释行作为心跳, 浏览器会忽略
+func (s *SSEStream) Heartbeat() error {
+	return s.write(func(w io.Writer) error {
+		_, err := io.WriteString(w, ": ping\n\n")
+		return err
+	})
+}
+
+func (s *SSEStream) write(fn func(w io.Writer) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.c.Request.Context().Err() != nil {
+		return ErrSSEClosed
+	}
+	if err := fn(s.c.Writer); err != nil {
+		return errors.Mark(err, ErrSSEClosed)
+	}
+	s.c.Writer.Flush()
+	return nil
+}
+
+// ServeSSE 将 events 中的事件推送给客户端, 直到 events 关闭、客户端断开或写入失败
+// 客户端断开时返回 ErrSSEClosed, events 关闭时返回 nil
+func ServeSSE(c *gin.Context, events <-chan Event, opts *SSEOptions) error {
+	if opts == nil {
+		opts = &SSEOptions{}
+	}
+	opts.setDefaults()
+	s := NewSSEStream(c, opts.Retry)
+	var heartbeat <-chan time.Time
+	if opts.Heartbeat > 0 {
+		ticker := time.NewTicker(opts.Heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+	for {
+		select {
+		case <-s.Done():
+			return ErrSSEClosed
+		case <-heartbeat:
+			if err := s.Heartbeat(); err != nil {
+				return err
+			}
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := s.Send(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// SSEFromReader 将 r 按行作为 event 事件推送, 适用于 exkube.Client.PodLogs(...).Stream(ctx) 等日志流
+// 客户端断开或推送失败时, r 实现了 io.Closer 则将其关闭, 使阻塞在读取上的协程退出
+func SSEFromReader(c *gin.Context, r io.Reader, event string, opts *SSEOptions) error {
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	events := make(chan Event)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64<<10), 1<<20)
+		for scanner.Scan() {
+			select {
+			case events <- Event{Event: event, Data: scanner.Text()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		errc <- scanner.Err()
+	}()
+	if err := ServeSSE(c, events, opts); err != nil {
+		cancel()
+		if closer, ok := r.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		return err
+	}
+	return <-errc
+}
+
+// ReplayBuffer 保存最近的事件, 用于客户端携带 Last-Event-ID 重连后补发
+type ReplayBuffer struct {
+	mu     sync.Mutex
+	size   int
+	seq    uint64
+	events []Event
+}
+
+// NewReplayBuffer 创建容量为 size 的环形缓冲
+func NewReplayBuffer(size int) *ReplayBuffer {
+	return &ReplayBuffer{size: size}
+}
+
+// Add 保存事件, ID 为空时分配自增ID, 返回保存后的事件
+func (b *ReplayBuffer) Add(ev Event) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	if ev.ID == "" {
+		ev.ID = strconv.FormatUint(b.seq, 10)
+	}
+	if b.size <= 0 {
+		return ev
+	}
+	if len(b.events) >= b.size {
+		b.events = append(b.events[:0], b.events[1:]...)
+	}
+	b.events = append(b.events, ev)
+	return ev
+}
+
+// Since 返回 lastID 之后的事件, lastID 为空返回 nil, lastID 已被淘汰时返回全部缓存事件
+func (b *ReplayBuffer) Since(lastID string) []Event {
+	if lastID == "" {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := len(b.events) - 1; i >= 0; i-- {
+		if b.events[i].ID == lastID {
+			return append([]Event(nil), b.events[i+1:]...)
+		}
+	}
+	return append([]Event(nil), b.events...)
+}
+
+// SSEBackpressure 订阅者消费过慢(缓冲已满)时的处理策略
+type SSEBackpressure int
+
+const (
+	// SSEDisconnect 断开慢订阅者, 客户端重连后通过 Last-Event-ID 补发
+	SSEDisconnect SSEBackpressure = iota
+	// SSEDropEvent 丢弃该订阅者的本条事件
+	SSEDropEvent
+)
+
+// SSEHubOptions SSEHub 配置
+type SSEHubOptions struct {
+	// Replay 重连补发缓存的事件数, 默认 100, 小于 0 表示不缓存
+	Replay int
+	// Buffer 每个订阅者的事件缓冲, 默认 16
+	Buffer int
+	// Backpressure 缓冲已满时的策略, 默认 SSEDisconnect
+	Backpressure SSEBackpressure
+}
+
+type sseSubscriber struct {
+	ch   chan Event
+	once sync.Once
+}
+
+func (s *sseSubscriber) close() {
+	s.once.Do(func() { close(s.ch) })
+}
+
+// SSEHub 将事件广播给所有订阅者
+type SSEHub struct {
+	mu     sync.Mutex
+	opts   SSEHubOptions
+	replay *ReplayBuffer
+	subs   map[*sseSubscriber]struct{}
+	closed bool
+}
+
+// NewSSEHub 创建广播中心
+func NewSSEHub(opts *SSEHubOptions) *SSEHub {
+	o := SSEHubOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Replay == 0 {
+		o.Replay = 100
+	}
+	if o.Buffer <= 0 {
+		o.Buffer = 16
+	}
+	return &SSEHub{opts: o, replay: NewReplayBuffer(o.Replay), subs: map[*sseSubscriber]struct{}{}}
+}
+
+// Publish 广播事件, 返回分配ID后的事件
+func (h *SSEHub) Publish(ev Event) Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ev = h.replay.Add(ev)
+	for sub := range h.subs {
+		select {
+		case sub.ch <- ev:
+		default:
+			if h.opts.Backpressure == SSEDisconnect {
+				delete(h.subs, sub)
+				sub.close()
+			}
+		}
+	}
+	return ev
+}
+
+// Subscribe 订阅事件, lastEventID 不为空时先补发之后的事件
+// 返回的 channel 在取消订阅、被判定为慢订阅者或 hub 关闭时关闭
+func (h *SSEHub) Subscribe(lastEventID string) (<-chan Event, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	missed := h.replay.Since(lastEventID)
+	sub := &sseSubscriber{ch: make(chan Event, h.opts.Buffer+len(missed))}
+	for _, ev := range missed {
+		sub.ch <- ev
+	}
+	if h.closed {
+		sub.close()
+		return sub.ch, func() {}
+	}
+	h.subs[sub] = struct{}{}
+	return sub.ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs, sub)
+		sub.close()
+	}
+}
+
+// Len 当前订阅者数量
+func (h *SSEHub) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// Close 关闭所有订阅, 之后的订阅会立即结束
+func (h *SSEHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.subs {
+		delete(h.subs, sub)
+		sub.close()
+	}
+}
+
+// Handler 订阅 hub 并推送给客户端, 支持 Last-Event-ID 重连补发
+func (h *SSEHub) Handler(opts *SSEOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		events, cancel := h.Subscribe(lastEventID(c))
+		defer cancel()
+		_ = ServeSSE(c, events, opts)
+	}
+}
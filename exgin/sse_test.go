@@ -0,0 +1,133 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeSSE(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	events := make(chan Event, 2)
+	events <- Event{ID: "1", Event: "progress", Data: map[string]int{"ready": 1}}
+	events <- Event{ID: "2", Data: "done"}
+	close(events)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/events", nil)
+	require.NoError(t, ServeSSE(c, events, &SSEOptions{Retry: 3 * time.Second}))
+
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "retry:3000\n\nid:1\nevent:progress\ndata:{\"ready\":1}\n\nid:2\ndata:done\n\n", w.Body.String())
+}
+
+func TestServeSSEDisconnect(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx, cancel := context.WithCancel(context.Background())
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+
+	errc := make(chan error, 1)
+	go func() { errc <- ServeSSE(c, make(chan Event), &SSEOptions{Heartbeat: time.Millisecond}) }()
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-errc:
+		assert.ErrorIs(t, err, ErrSSEClosed)
+	case <-time.After(time.Second):
+		t.Fatal("ServeSSE did not return after client disconnect")
+	}
+}
+
+func TestSSEFromReader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/logs", nil)
+	require.NoError(t, SSEFromReader(c, strings.NewReader("line 1\nline 2\n"), "log", nil))
+	assert.Equal(t, "event:log\ndata:line 1\n\nevent:log\ndata:line 2\n\n", w.Body.String())
+}
+
+func TestSSEFromReaderClientGone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx, cancel := context.WithCancel(context.Background())
+	c.Request = httptest.NewRequest(http.MethodGet, "/logs", nil).WithContext(ctx)
+	pr, pw := io.Pipe()
+
+	done := make(chan error, 1)
+	go func() { done <- SSEFromReader(c, pr, "log", nil) }()
+	_, err := pw.Write([]byte("line 1\n"))
+	require.NoError(t, err)
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, ErrSSEClosed)
+	case <-time.After(time.Second):
+		t.Fatal("SSEFromReader did not return after client disconnect")
+	}
+	_, err = pw.Write([]byte("line 2\n"))
+	assert.ErrorIs(t, err, io.ErrClosedPipe, "reader closed so the scanning goroutine exits")
+}
+
+func TestSSEHubReplay(t *testing.T) {
+	hub := NewSSEHub(&SSEHubOptions{Replay: 2})
+	for _, d := range []string{"a", "b", "c"} {
+		hub.Publish(Event{Data: d})
+	}
+
+	events, cancel := hub.Subscribe("2")
+	assert.Equal(t, Event{ID: "3", Data: "c"}, <-events)
+
+	hub.Publish(Event{Data: "d"})
+	assert.Equal(t, Event{ID: "4", Data: "d"}, <-events)
+
+	resumed, cancel2 := hub.Subscribe("1")
+	defer cancel2()
+	assert.Len(t, resumed, 2, "evicted id replays the whole buffer")
+
+	assert.Equal(t, 2, hub.Len())
+	cancel()
+	_, ok := <-events
+	assert.False(t, ok)
+	assert.Equal(t, 1, hub.Len())
+}
+
+func TestSSEHubBackpressure(t *testing.T) {
+	hub := NewSSEHub(&SSEHubOptions{Buffer: 1})
+	slow, _ := hub.Subscribe("")
+	hub.Publish(Event{Data: 1})
+	hub.Publish(Event{Data: 2})
+	assert.Equal(t, 1, (<-slow).Data)
+	_, ok := <-slow
+	assert.False(t, ok, "slow subscriber is disconnected")
+	assert.Equal(t, 0, hub.Len())
+
+	hub = NewSSEHub(&SSEHubOptions{Buffer: 1, Backpressure: SSEDropEvent})
+	slow, cancel := hub.Subscribe("")
+	defer cancel()
+	hub.Publish(Event{Data: 1})
+	hub.Publish(Event{Data: 2})
+	assert.Equal(t, 1, (<-slow).Data)
+	assert.Equal(t, 1, hub.Len(), "subscriber kept, event dropped")
+
+	hub.Close()
+	_, ok = <-slow
+	assert.False(t, ok)
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	zh_translations "github.com/go-playground/validator/v10/translations/zh"
+)
+
+const (
+	defaultLocale = "zh"
+	transKey      = "trans"
+)
+
+var validatorOnce sync.Once
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors 参数校验失败时返回的字段错误列表
+type ValidationErrors []FieldError
+
+func (ve ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(ve))
+	for _, fe := range ve {
+		msgs = append(msgs, fe.Message)
+	}
+	return "参数不合法: " + strings.Join(msgs, "; ")
+}
+
+// setupValidator 为 gin 默认校验器注册 json 字段名和 en/zh 默认翻译, 只执行一次
+func setupValidator() {
+	validatorOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		v.RegisterTagNameFunc(jsonTagName)
+		if trans, found := uni.GetTranslator("en"); found {
+			_ = en_translations.RegisterDefaultTranslations(v, trans)
+		}
+		if trans, found := uni.GetTranslator("zh"); found {
+			_ = zh_translations.RegisterDefaultTranslations(v, trans)
+		}
+	})
+}
+
+// jsonTagName 使用 json tag 作为字段名, 未设置时回退到 form tag 和结构体字段名
+func jsonTagName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// RegisterTagTranslation 为指定校验 tag 注册自定义翻译
+// text 中 {0} 为字段名, {1} 为 tag 参数, 例如 "{0}必须是合法的手机号"
+func RegisterTagTranslation(tag, locale, text string) error {
+	setupValidator()
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("exgin: binding validator is not go-playground/validator")
+	}
+	trans, found := uni.GetTranslator(locale)
+	if !found {
+		return errors.Newf("exgin: unsupported locale %s", locale)
+	}
+	return v.RegisterTranslation(tag, trans, func(t ut.Translator) error {
+		return t.Add(tag, text, true)
+	}, func(t ut.Translator, fe validator.FieldError) string {
+		msg, err := t.T(tag, fe.Field(), fe.Param())
+		if err != nil {
+			return fe.Error()
+		}
+		return msg
+	})
+}
+
+// parseLocale 从 Accept-Language 中选出第一个支持的语言, 例如 "zh-CN,zh;q=0.9,en;q=0.8" -> zh
+func parseLocale(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		base, _, _ := strings.Cut(tag, "-")
+		base = strings.ToLower(strings.TrimSpace(base))
+		if base == "" {
+			continue
+		}
+		if _, found := uni.GetTranslator(base); found {
+			return base
+		}
+	}
+	return ""
+}
+
+// translator 获取当前请求的翻译器, 优先使用 Translations 中间件设置的结果
+func translator(c *gin.Context) ut.Translator {
+	if v, exists := c.Get(transKey); exists {
+		if trans, ok := v.(ut.Translator); ok {
+			return trans
+		}
+	}
+	locale := c.GetHeader("locale")
+	if locale == "" {
+		locale = parseLocale(c.GetHeader("Accept-Language"))
+	}
+	if locale == "" {
+		locale = defaultLocale
+	}
+	trans, found := uni.GetTranslator(locale)
+	if !found {
+		trans, _ = uni.GetTranslator(defaultLocale)
+	}
+	return trans
+}
+
+// TranslateError 将校验错误转换为按请求语言翻译的字段错误列表
+// 非校验错误返回 nil
+func TranslateError(c *gin.Context, err error) ValidationErrors {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return nil
+	}
+	setupValidator()
+	trans := translator(c)
+	result := make(ValidationErrors, 0, len(verrs))
+	for _, fe := range verrs {
+		result = append(result, FieldError{
+			Field:   fieldPath(fe),
+			Tag:     fe.Tag(),
+			Param:   fe.Param(),
+			Message: fe.Translate(trans),
+		})
+	}
+	return result
+}
+
+// fieldPath 去掉顶层结构体名, 保留嵌套字段路径, 例如 User.address.city -> address.city
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if _, rest, ok := strings.Cut(ns, "."); ok {
+		return rest
+	}
+	return fe.Field()
+}
+
+func bindError(c *gin.Context, err error) error {
+	if verrs := TranslateError(c, err); len(verrs) > 0 {
+		return verrs
+	}
+	return fmt.Errorf("参数不合法: %w", err)
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bindAddress struct {
+	City string `json:"city" binding:"required"`
+}
+
+type bindUser struct {
+	UserName string      `json:"user_name" binding:"required,min=3"`
+	Phone    string      `json:"phone" binding:"required,mobile"`
+	Address  bindAddress `json:"address"`
+}
+
+type validationResp struct {
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Data    []FieldError `json:"data"`
+}
+
+func newBindEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ExRecovery(), Translations())
+	r.POST("/bind", func(c *gin.Context) {
+		var u bindUser
+		Bind(c, &u)
+		SucessResponse(c, u)
+	})
+	return r
+}
+
+func TestBindFieldErrors(t *testing.T) {
+	v := binding.Validator.Engine().(*validator.Validate)
+	require.NoError(t, v.RegisterValidation("mobile", func(fl validator.FieldLevel) bool {
+		return len(fl.Field().String()) == 11
+	}))
+	require.NoError(t, RegisterTagTranslation("mobile", "zh", "{0}必须是合法的手机号"))
+	require.NoError(t, RegisterTagTranslation("mobile", "en", "{0} must be a valid mobile number"))
+	r := newBindEngine()
+
+	tests := []struct {
+		name     string
+		lang     string
+		contains map[string]string
+	}{
+		{
+			name: "zh by default",
+			contains: map[string]string{
+				"user_name":    "user_name长度必须至少为3个字符",
+				"phone":        "phone必须是合法的手机号",
+				"address.city": "city为必填字段",
+			},
+		},
+		{
+			name: "en by accept-language",
+			lang: "en-US,en;q=0.9",
+			contains: map[string]string{
+				"user_name":    "user_name must be at least 3 characters in length",
+				"phone":        "phone must be a valid mobile number",
+				"address.city": "city is a required field",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/bind", strings.NewReader(`{"user_name":"ab","phone":"x"}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.lang != "" {
+				req.Header.Set("Accept-Language", tt.lang)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var resp validationResp
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, 400, resp.Code)
+			got := map[string]string{}
+			for _, fe := range resp.Data {
+				got[fe.Field] = fe.Message
+			}
+			assert.Equal(t, tt.contains, got)
+		})
+	}
+}
+
+func TestBindWithErrSyntax(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{bad`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	var u bindUser
+	err := BindWithErr(c, &u)
+	require.Error(t, err)
+	var verrs ValidationErrors
+	assert.NotErrorAs(t, err, &verrs)
+	assert.Contains(t, err.Error(), "参数不合法")
+}
+
+func TestParseLocale(t *testing.T) {
+	assert.Equal(t, "zh", parseLocale("zh-CN,zh;q=0.9,en;q=0.8"))
+	assert.Equal(t, "en", parseLocale("fr-FR, en;q=0.5"))
+	assert.Equal(t, "", parseLocale("fr"))
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exgin
+
+import (
+	"bytes"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bodyCaptureWriter 在写出响应的同时保留一份响应体
+// limit 大于 0 时最多保留 limit 字节, truncated 标记是否被截断
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body      *bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (w *bodyCaptureWriter) capture(b []byte) {
+	if w.limit <= 0 {
+		w.body.Write(b)
+		return
+	}
+	remain := w.limit - w.body.Len()
+	if remain <= 0 {
+		w.truncated = w.truncated || len(b) > 0
+		return
+	}
+	if len(b) > remain {
+		b = b[:remain]
+		w.truncated = true
+	}
+	w.body.Write(b)
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
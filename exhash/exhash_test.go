@@ -288,6 +288,11 @@ func TestGenSha256(t *testing.T) {
 	}
 }
 
+func TestHmacSha256(t *testing.T) {
+	got := HmacSha256("key", "The quick brown fox jumps over the lazy dog")
+	assert.Equal(t, "f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8", got)
+}
+
 func TestGenSha512(t *testing.T) {
 	tests := []struct {
 		name  string
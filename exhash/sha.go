@@ -14,6 +14,7 @@
 package exhash
 
 import (
+	"crypto/hmac"
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
@@ -30,6 +31,13 @@ func GenSha256(code string) string {
 	return hex.EncodeToString(s.Sum(nil))
 }
 
+// HmacSha256 生成hex编码的HMAC-SHA256
+func HmacSha256(key, data string) string {
+	h := hmac.New(sha256.New, []byte(key))
+	h.Write([]byte(data))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // GenSha512 生成sha512
 func GenSha512(code string) string {
 	s := sha512.New()
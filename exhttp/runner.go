@@ -0,0 +1,274 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exhttp
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/gops/agent"
+	"github.com/sirupsen/logrus"
+)
+
+const unixPrefix = "unix://"
+
+// Server describes one HTTP server managed by Runner.
+type Server struct {
+	// Name is used in logs, e.g. "api" or "admin".
+	Name string
+	// Addr is "host:port", or "unix:///path/to.sock" for a unix socket.
+	Addr    string
+	Handler http.Handler
+	// TLSCertFile and TLSKeyFile enable TLS; the certificate is reloaded when the files change.
+	TLSCertFile string
+	TLSKeyFile  string
+	// H2C enables HTTP/2 over cleartext, ignored when TLS is enabled.
+	H2C bool
+	// HTTPServer optionally provides timeouts and other settings; Addr and Handler are overwritten,
+	// TLSConfig is cloned before the certificate and ALPN settings are applied.
+	HTTPServer *http.Server
+
+	srv *http.Server
+	ln  net.Listener
+}
+
+// ListenAddr returns the bound address after the runner is ready.
+func (s *Server) ListenAddr() net.Addr {
+	if s.ln == nil {
+		return nil
+	}
+	return s.ln.Addr()
+}
+
+func (s *Server) listen() error {
+	srv := s.HTTPServer
+	if srv == nil {
+		srv = &http.Server{ReadHeaderTimeout: 10 * time.Second}
+	}
+	srv.Addr = s.Addr
+	srv.Handler = s.Handler
+
+	var (
+		ln  net.Listener
+		err error
+	)
+	if path, ok := strings.CutPrefix(s.Addr, unixPrefix); ok {
+		// remove a stale socket left by a previous crash
+		_ = os.Remove(path)
+		ln, err = net.Listen("unix", path)
+	} else {
+		ln, err = net.Listen("tcp", s.Addr)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "%s listen %s", s.Name, s.Addr)
+	}
+
+	if s.TLSCertFile != "" {
+		reloader, err := NewCertReloader(s.TLSCertFile, s.TLSKeyFile)
+		if err != nil {
+			ln.Close()
+			return errors.Wrapf(err, "%s load tls certificate", s.Name)
+		}
+		if srv.TLSConfig == nil {
+			srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		} else {
+			srv.TLSConfig = srv.TLSConfig.Clone()
+		}
+		srv.TLSConfig.GetCertificate = reloader.GetCertificate
+		srv.TLSConfig.NextProtos = []string{"h2", "http/1.1"}
+		ln = tls.NewListener(ln, srv.TLSConfig)
+	} else if s.H2C {
+		srv.Protocols = new(http.Protocols)
+		srv.Protocols.SetHTTP1(true)
+		srv.Protocols.SetUnencryptedHTTP2(true)
+	}
+	s.srv, s.ln = srv, ln
+	return nil
+}
+
+// Hook is a named shutdown step.
+type Hook struct {
+	Name string
+	Fn   func(ctx context.Context) error
+}
+
+// RunnerOptions configures Runner.
+type RunnerOptions struct {
+	// ShutdownTimeout bounds the whole shutdown sequence, defaults to 30s.
+	ShutdownTimeout time.Duration
+	// PreStopDelay keeps serving after the signal so load balancers can remove the instance, defaults to 0.
+	PreStopDelay time.Duration
+	// Signals defaults to SIGINT and SIGTERM.
+	Signals []os.Signal
+	// GopsAddr starts the gops agent when not empty, e.g. "127.0.0.1:32388".
+	GopsAddr string
+}
+
+// Runner starts several HTTP servers and shuts them down in order:
+// stop accepting connections and drain in-flight requests on all servers,
+// then run shutdown hooks in registration order (flush logs, close cache clients, ...),
+// finally stop the gops agent.
+type Runner struct {
+	opts    RunnerOptions
+	servers []*Server
+	hooks   []Hook
+	ready   chan struct{}
+	once    sync.Once
+	err     error
+}
+
+// NewRunner creates a runner.
+func NewRunner(opts *RunnerOptions) *Runner {
+	o := RunnerOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.ShutdownTimeout <= 0 {
+		o.ShutdownTimeout = 30 * time.Second
+	}
+	if len(o.Signals) == 0 {
+		o.Signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+	return &Runner{opts: o, ready: make(chan struct{})}
+}
+
+// AddServer registers a server; must be called before Run.
+func (r *Runner) AddServer(s *Server) *Runner {
+	r.servers = append(r.servers, s)
+	return r
+}
+
+// OnShutdown registers a hook that runs after all servers are drained.
+func (r *Runner) OnShutdown(name string, fn func(ctx context.Context) error) *Runner {
+	r.hooks = append(r.hooks, Hook{Name: name, Fn: fn})
+	return r
+}
+
+// Ready is closed once all servers are listening, or once Run fails to start them;
+// check StartErr to tell the two apart.
+func (r *Runner) Ready() <-chan struct{} {
+	return r.ready
+}
+
+// StartErr returns the error that prevented the servers from starting, valid after Ready is closed.
+func (r *Runner) StartErr() error {
+	return r.err
+}
+
+// Run starts all servers and blocks until ctx is done, a signal is received or a server fails,
+// then performs the ordered shutdown. It returns the server error, or the first shutdown error.
+func (r *Runner) Run(ctx context.Context) error {
+	for i, s := range r.servers {
+		if err := s.listen(); err != nil {
+			for _, started := range r.servers[:i] {
+				started.ln.Close()
+			}
+			r.once.Do(func() {
+				r.err = err
+				close(r.ready)
+			})
+			return err
+		}
+	}
+	if r.opts.GopsAddr != "" {
+		if err := agent.Listen(agent.Options{Addr: r.opts.GopsAddr}); err != nil {
+			logrus.Warnf("start gops agent failed: %v", err)
+		}
+	}
+
+	errc := make(chan error, len(r.servers))
+	for _, s := range r.servers {
+		go func(s *Server) {
+			logrus.Infof("%s server listen on %s", s.Name, s.ListenAddr())
+			if err := s.srv.Serve(s.ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errc <- errors.Wrapf(err, "%s server", s.Name)
+			}
+		}(s)
+	}
+	r.once.Do(func() { close(r.ready) })
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, r.opts.Signals...)
+	defer signal.Stop(quit)
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		logrus.Info("context done, shutting down")
+	case sig := <-quit:
+		logrus.Infof("receive signal %s", sig)
+		if r.opts.PreStopDelay > 0 {
+			time.Sleep(r.opts.PreStopDelay)
+		}
+	case runErr = <-errc:
+		logrus.Errorf("server failed: %v", runErr)
+	}
+
+	if err := r.shutdown(); runErr == nil {
+		runErr = err
+	}
+	return runErr
+}
+
+func (r *Runner) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.opts.ShutdownTimeout)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	record := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for _, s := range r.servers {
+		wg.Add(1)
+		go func(s *Server) {
+			defer wg.Done()
+			if err := s.srv.Shutdown(ctx); err != nil {
+				logrus.Errorf("%s server shutdown failed: %v", s.Name, err)
+				_ = s.srv.Close()
+				record(errors.Wrapf(err, "%s server shutdown", s.Name))
+			}
+			if path, ok := strings.CutPrefix(s.Addr, unixPrefix); ok {
+				_ = os.Remove(path)
+			}
+			logrus.Infof("%s server stopped", s.Name)
+		}(s)
+	}
+	wg.Wait()
+
+	for _, h := range r.hooks {
+		if err := h.Fn(ctx); err != nil {
+			logrus.Errorf("shutdown hook %s failed: %v", h.Name, err)
+			record(errors.Wrapf(err, "shutdown hook %s", h.Name))
+		}
+	}
+	if r.opts.GopsAddr != "" {
+		agent.Close()
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		logrus.Warn("shutdown timeout")
+	}
+	logrus.Info("server exited.")
+	return firstErr
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exhttp
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunnerDrainsAndRunsHooksInOrder(t *testing.T) {
+	started := make(chan struct{})
+	api := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = io.WriteString(w, "done")
+	})
+	sock := filepath.Join(t.TempDir(), "admin.sock")
+
+	var order []string
+	r := NewRunner(&RunnerOptions{ShutdownTimeout: 5 * time.Second})
+	apiSrv := &Server{Name: "api", Addr: "127.0.0.1:0", Handler: api}
+	r.AddServer(apiSrv).
+		AddServer(&Server{Name: "admin", Addr: "unix://" + sock, Handler: http.NotFoundHandler()}).
+		OnShutdown("flush-logs", func(context.Context) error {
+			order = append(order, "flush-logs")
+			return nil
+		}).
+		OnShutdown("close-cache", func(context.Context) error {
+			order = append(order, "close-cache")
+			return nil
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- r.Run(ctx) }()
+	<-r.Ready()
+
+	conn, err := net.Dial("unix", sock)
+	require.NoError(t, err, "unix socket is listening")
+	conn.Close()
+
+	respc := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + apiSrv.ListenAddr().String())
+		if err != nil {
+			respc <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		respc <- string(body)
+	}()
+	<-started
+	cancel()
+
+	assert.Equal(t, "done", <-respc, "in-flight request is drained")
+	require.NoError(t, <-done)
+	assert.Equal(t, []string{"flush-logs", "close-cache"}, order)
+	assert.NoFileExists(t, sock)
+}
+
+func TestRunnerListenError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	r := NewRunner(nil).AddServer(&Server{Name: "api", Addr: ln.Addr().String(), Handler: http.NotFoundHandler()})
+	assert.Error(t, r.Run(context.Background()))
+	// 启动失败时 Ready 同样关闭, 不会让等待方永久阻塞
+	select {
+	case <-r.Ready():
+	default:
+		t.Fatal("Ready not closed after listen error")
+	}
+	assert.Error(t, r.StartErr())
+}
+
+func writeSelfSignedCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestServerKeepsCallerTLSConfig(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t)
+	cfg := &tls.Config{MinVersion: tls.VersionTLS13, NextProtos: []string{"acme-tls/1"}}
+	s := &Server{
+		Name:        "api",
+		Addr:        "127.0.0.1:0",
+		Handler:     http.NotFoundHandler(),
+		TLSCertFile: certFile,
+		TLSKeyFile:  keyFile,
+		HTTPServer:  &http.Server{TLSConfig: cfg},
+	}
+	require.NoError(t, s.listen())
+	defer s.ln.Close()
+
+	assert.Nil(t, cfg.GetCertificate)
+	assert.Equal(t, []string{"acme-tls/1"}, cfg.NextProtos)
+	assert.NotNil(t, s.srv.TLSConfig.GetCertificate)
+	assert.Equal(t, uint16(tls.VersionTLS13), s.srv.TLSConfig.MinVersion)
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exhttp
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ergoapi/util/exhash"
+	"github.com/ergoapi/util/expass"
+)
+
+// Signature headers shared by Signer and exgin.SignatureAuth.
+const (
+	SignAlgorithm       = "EX-HMAC-SHA256"
+	HeaderSignKeyID     = "X-Ex-Key-Id"
+	HeaderSignTimestamp = "X-Ex-Timestamp"
+	HeaderSignNonce     = "X-Ex-Nonce"
+	HeaderSignature     = "X-Ex-Signature"
+)
+
+// StringToSign builds the canonical string signed by both sides:
+//
+//	EX-HMAC-SHA256
+//	<unix timestamp>
+//	<nonce>
+//	<METHOD>
+//	<escaped path>
+//	<query sorted by key then value>
+//	<hex sha256 of body>
+func StringToSign(method, path string, query url.Values, body []byte, timestamp, nonce string) string {
+	if path == "" {
+		path = "/"
+	}
+	return strings.Join([]string{
+		SignAlgorithm,
+		timestamp,
+		nonce,
+		strings.ToUpper(method),
+		path,
+		canonicalQuery(query),
+		exhash.GenSha256(string(body)),
+	}, "\n")
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(url.QueryEscape(k))
+			b.WriteByte('=')
+			b.WriteString(url.QueryEscape(v))
+		}
+	}
+	return b.String()
+}
+
+// Sign returns the hex HMAC-SHA256 signature of stringToSign.
+func Sign(secret, stringToSign string) string {
+	return exhash.HmacSha256(secret, stringToSign)
+}
+
+// Signer signs outgoing requests for exgin.SignatureAuth.
+type Signer struct {
+	KeyID  string
+	Secret string
+	// Now defaults to time.Now, overridable in tests.
+	Now func() time.Time
+}
+
+// NewSigner creates a signer with a shared key ID and secret.
+func NewSigner(keyID, secret string) *Signer {
+	return &Signer{KeyID: keyID, Secret: secret, Now: time.Now}
+}
+
+// Sign adds the signature headers to req. The body is read and restored.
+func (s *Signer) Sign(req *http.Request) error {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	nonce, err := expass.PwGenAlphaNum(16)
+	if err != nil {
+		return err
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	timestamp := strconv.FormatInt(now().Unix(), 10)
+	sts := StringToSign(req.Method, req.URL.EscapedPath(), req.URL.Query(), body, timestamp, nonce)
+
+	req.Header.Set(HeaderSignKeyID, s.KeyID)
+	req.Header.Set(HeaderSignTimestamp, timestamp)
+	req.Header.Set(HeaderSignNonce, nonce)
+	req.Header.Set(HeaderSignature, Sign(s.Secret, sts))
+	return nil
+}
+
+// RoundTripper wraps next (http.DefaultTransport if nil) and signs every request.
+func (s *Signer) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		// RoundTripper must not modify the caller's request
+		req = req.Clone(req.Context())
+		if err := s.Sign(req); err != nil {
+			return nil, err
+		}
+		return next.RoundTrip(req)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exhttp
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ergoapi/util/exctx"
+
+	"github.com/cockroachdb/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// Trace propagation headers written by TraceTransport.
+const (
+	HeaderTraceparent = "traceparent"
+	HeaderTraceID     = "X-Trace-Id"
+)
+
+var (
+	promClientLabels = []string{
+		"status_code",
+		"host",
+		"method",
+	}
+	promClientOnce       sync.Once
+	promClientReqCount   *prometheus.CounterVec
+	promClientReqLatency *prometheus.HistogramVec
+)
+
+// registerClientMetrics registers the exhttp_client_* collectors with the default
+// registry the first time a TraceTransport is built, so importing exhttp alone
+// does not touch the registry. Collectors already registered elsewhere are reused.
+func registerClientMetrics() {
+	promClientOnce.Do(func() {
+		promClientReqCount = registerOrReuse(prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "exhttp",
+				Name:      "client_req_count",
+				Help:      "outbound http request count",
+			}, promClientLabels,
+		))
+		promClientReqLatency = registerOrReuse(prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "exhttp",
+				Name:      "client_req_latency",
+				Help:      "outbound http request latency in seconds",
+			}, promClientLabels,
+		))
+	})
+}
+
+func registerOrReuse[T prometheus.Collector](c T) T {
+	if err := prometheus.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
+
+// TraceOptions configures TraceTransport.
+type TraceOptions struct {
+	// Logger defaults to logrus.StandardLogger().
+	Logger *logrus.Logger
+	// DisableLog skips the per-request log line; spans and metrics are still recorded.
+	DisableLog bool
+}
+
+// TraceTransport wraps next (http.DefaultTransport if nil) so that every request
+// continues the exctx trace found in its context: a client span is started as a
+// child of the current span or TraceContext, traceparent and X-Trace-Id are
+// injected, and latency, status and errors are recorded as span attributes,
+// Prometheus metrics and a log line (Debug, or Warn on errors and 5xx).
+func TraceTransport(next http.RoundTripper, opts *TraceOptions) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	o := TraceOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Logger == nil {
+		o.Logger = logrus.StandardLogger()
+	}
+	registerClientMetrics()
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		ctx, span := exctx.StartSpan(req.Context(), req.Method+" "+req.URL.Host,
+			exctx.WithSpanKind(exctx.SpanKindClient),
+			exctx.WithStartTime(start),
+			exctx.WithAttributes(map[string]any{
+				"http.request.method": req.Method,
+				"server.address":      req.URL.Host,
+				"url.path":            req.URL.Path,
+			}))
+		// RoundTripper must not modify the caller's request
+		req = req.Clone(ctx)
+		req.Header.Set(HeaderTraceparent, span.Traceparent())
+		req.Header.Set(HeaderTraceID, span.TraceID())
+
+		resp, err := next.RoundTrip(req)
+		latency := time.Since(start)
+
+		status := "error"
+		if err != nil {
+			span.RecordError(err)
+		} else {
+			status = strconv.Itoa(resp.StatusCode)
+			span.SetAttribute("http.response.status_code", resp.StatusCode)
+			if resp.StatusCode >= http.StatusInternalServerError {
+				span.SetStatus(exctx.StatusError, resp.Status)
+			}
+		}
+		span.End()
+
+		labels := []string{status, req.URL.Host, req.Method}
+		promClientReqCount.WithLabelValues(labels...).Inc()
+		promClientReqLatency.WithLabelValues(labels...).Observe(latency.Seconds())
+
+		if !o.DisableLog {
+			entry := o.Logger.WithFields(logrus.Fields{
+				"traceID":    span.TraceID(),
+				"SpanID":     span.SpanID(),
+				"Tag":        "exhttp",
+				"method":     req.Method,
+				"host":       req.URL.Host,
+				"path":       req.URL.Path,
+				"statuscode": status,
+				"latency":    latency,
+			})
+			switch {
+			case err != nil:
+				entry.Warnf("outbound request failed: %v", err)
+			case resp.StatusCode >= http.StatusInternalServerError:
+				entry.Warn("outbound request")
+			default:
+				entry.Debug("outbound request")
+			}
+		}
+		return resp, err
+	})
+}
+
+// WithTracing returns a shallow copy of c (a new client if nil) whose transport
+// is wrapped by TraceTransport; c itself is left untouched.
+func WithTracing(c *http.Client, opts *TraceOptions) *http.Client {
+	out := &http.Client{}
+	if c != nil {
+		*out = *c
+	}
+	out.Transport = TraceTransport(out.Transport, opts)
+	return out
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ergoapi/util/exctx"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceTransportPropagates(t *testing.T) {
+	exp := exctx.NewMemoryExporter()
+	exctx.SetExporters(exp)
+	defer exctx.SetExporters()
+
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	logger, hook := test.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+	client := WithTracing(srv.Client(), &TraceOptions{Logger: logger})
+
+	ctx, parent := exctx.StartSpan(context.Background(), "handler")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/users", nil)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	parent.End()
+
+	// 调用方的请求头不被修改
+	assert.Empty(t, req.Header.Get(HeaderTraceparent))
+
+	spans := exp.Spans()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, exctx.SpanKindClient, span.Kind)
+	assert.Equal(t, parent.TraceID(), span.TraceID)
+	assert.Equal(t, parent.SpanID(), span.ParentSpanID)
+	assert.Equal(t, http.StatusBadGateway, span.Attributes["http.response.status_code"])
+	assert.Equal(t, exctx.StatusError, span.StatusCode)
+
+	assert.Equal(t, span.TraceID, got.Get(HeaderTraceID))
+	tc, ok := exctx.ParseTraceparent(got.Get(HeaderTraceparent))
+	require.True(t, ok)
+	assert.Equal(t, span.SpanID, tc.SpanID)
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+	assert.Equal(t, "502", entry.Data["statuscode"])
+	assert.Equal(t, "/users", entry.Data["path"])
+}
+
+func TestTraceTransportFromTraceContext(t *testing.T) {
+	var got string
+	next := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		got = r.Header.Get(HeaderTraceparent)
+		return nil, errors.New("connection refused")
+	})
+	tc := &exctx.TraceContext{}
+	tc.TraceID, tc.SpanID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	ctx := exctx.SetTraceContext(context.Background(), tc)
+
+	exp := exctx.NewMemoryExporter()
+	exctx.SetExporters(exp)
+	defer exctx.SetExporters()
+
+	rt := TraceTransport(next, &TraceOptions{DisableLog: true})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "http://bark.local/push", nil)
+	_, err := rt.RoundTrip(req)
+	require.Error(t, err)
+
+	span := exp.Spans()[0]
+	assert.Equal(t, tc.TraceID, span.TraceID)
+	assert.Equal(t, tc.SpanID, span.ParentSpanID)
+	assert.Equal(t, exctx.StatusError, span.StatusCode)
+	assert.Contains(t, got, tc.TraceID)
+}
+
+func TestWithTracingKeepsClient(t *testing.T) {
+	orig := &http.Client{}
+	c := WithTracing(orig, nil)
+	assert.Nil(t, orig.Transport)
+	assert.NotNil(t, c.Transport)
+	assert.NotNil(t, WithTracing(nil, nil).Transport)
+}
+
+func TestRegisterOrReuse(t *testing.T) {
+	registerClientMetrics()
+	// 重复注册同名指标时复用已有的 collector
+	dup := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "exhttp",
+		Name:      "client_req_count",
+		Help:      "outbound http request count",
+	}, promClientLabels)
+	assert.Same(t, promClientReqCount, registerOrReuse(dup))
+}
@@ -8,6 +8,7 @@
 package exjwt
 
 import (
+	"fmt"
 	"os"
 	"time"
 
@@ -58,6 +59,17 @@ func AuthWithSecret(username string, uuid string, key []byte) (t string, err err
 		return "", ErrEmptySecret
 	}
 
+	claims := newClaims(username, uuid)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	t, err = token.SignedString(key)
+	if err != nil {
+		return "", errors.Wrap(err, "JWT generate failure")
+	}
+	return t, nil
+}
+
+// newClaims builds claims with the TTL policy: admin/root -> 4h, others -> 7d.
+func newClaims(username string, uuid string) *Claims {
 	now := time.Now()
 	var ttl time.Duration
 	if username == "admin" || username == "root" {
@@ -65,8 +77,7 @@ func AuthWithSecret(username string, uuid string, key []byte) (t string, err err
 	} else {
 		ttl = 7 * 24 * time.Hour
 	}
-
-	claims := &Claims{
+	return &Claims{
 		Username: username,
 		UUID:     uuid,
 		RegisteredClaims: jwt.RegisteredClaims{
@@ -76,12 +87,6 @@ func AuthWithSecret(username string, uuid string, key []byte) (t string, err err
 			Subject:   uuid,
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	t, err = token.SignedString(key)
-	if err != nil {
-		return "", errors.Wrap(err, "JWT generate failure")
-	}
-	return t, nil
 }
 
 func Parse(ts string) (jwt.MapClaims, error) {
@@ -99,15 +104,58 @@ func ParseWithSecret(ts string, key []byte) (jwt.MapClaims, error) {
 	}
 
 	c := &Claims{}
-	token, err := jwt.ParseWithClaims(ts, c, func(token *jwt.Token) (any, error) {
+	token, err := jwt.ParseWithClaims(ts, c, hs256Keyfunc(key),
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithLeeway(time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	return validClaims(token, c)
+}
+
+// ParseMap is like Parse but returns every claim of the verified token,
+// including custom ones such as roles or scope.
+func ParseMap(ts string) (jwt.MapClaims, error) {
+	key, err := secretFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return ParseMapWithSecret(ts, key)
+}
+
+// ParseMapWithSecret is like ParseWithSecret but returns every claim of the verified token.
+func ParseMapWithSecret(ts string, key []byte) (jwt.MapClaims, error) {
+	if len(key) == 0 {
+		return nil, ErrEmptySecret
+	}
+	return parseMapClaims(ts, hs256Keyfunc(key), []string{jwt.SigningMethodHS256.Alg()})
+}
+
+func hs256Keyfunc(key []byte) jwt.Keyfunc {
+	return func(token *jwt.Token) (any, error) {
 		if m, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || m != jwt.SigningMethodHS256 {
 			return nil, ErrAlgMismatch
 		}
 		return key, nil
-	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithLeeway(time.Minute))
+	}
+}
+
+// parseMapClaims verifies ts and returns the full claim set; expired tokens match ErrExpired.
+func parseMapClaims(ts string, keyfunc jwt.Keyfunc, algs []string) (jwt.MapClaims, error) {
+	mc := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(ts, mc, keyfunc, jwt.WithValidMethods(algs), jwt.WithLeeway(time.Minute))
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, fmt.Errorf("%w: %w", ErrExpired, err)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	return mc, nil
+}
+
+func validClaims(token *jwt.Token, c *Claims) (jwt.MapClaims, error) {
 	if !token.Valid {
 		return nil, ErrInvalidToken
 	}
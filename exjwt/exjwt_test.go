@@ -178,3 +178,37 @@ func Example() {
 	fmt.Printf("username=%v uuid=%v sub=%v\n", claims["username"], claims["uuid"], claims["sub"])
 	// Output: username=alice uuid=uuid-123 sub=uuid-123
 }
+
+func TestParseMapKeepsCustomClaims(t *testing.T) {
+	key := []byte("secret")
+	sign := func(exp time.Time) string {
+		ts, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub":   "u1",
+			"roles": []string{"admin"},
+			"scope": "read write",
+			"exp":   exp.Unix(),
+		}).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+
+	claims, err := ParseMapWithSecret(sign(time.Now().Add(time.Hour)), key)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if roles, _ := claims["roles"].([]any); len(roles) != 1 || roles[0] != "admin" {
+		t.Fatalf("roles lost: %v", claims)
+	}
+	if claims["scope"] != "read write" || claims["sub"] != "u1" {
+		t.Fatalf("claims mismatch: %v", claims)
+	}
+
+	if _, err := ParseMapWithSecret(sign(time.Now().Add(-time.Hour)), key); !errors.Is(err, ErrExpired) {
+		t.Fatalf("expected ErrExpired, got %v", err)
+	}
+	if _, err := ParseMapWithSecret(sign(time.Now().Add(time.Hour)), []byte("other")); err == nil {
+		t.Fatal("expected signature error")
+	}
+}
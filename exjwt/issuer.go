@@ -0,0 +1,232 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exjwt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TypedClaims holds the registered claims plus custom claims T.
+// The JSON fields of T are flattened into the token payload next to sub/exp/...;
+// registered claims win when names collide.
+type TypedClaims[T any] struct {
+	jwt.RegisteredClaims
+	// SessionID is the sid claim, set by TokenManager to the refresh token family.
+	SessionID string
+	Custom    T
+}
+
+// MarshalJSON flattens Custom and the registered claims into one object.
+func (c TypedClaims[T]) MarshalJSON() ([]byte, error) {
+	out := map[string]json.RawMessage{}
+	custom, err := json.Marshal(c.Custom)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(custom, []byte("null")) {
+		if err := json.Unmarshal(custom, &out); err != nil {
+			return nil, errors.Wrap(err, "jwt: custom claims must encode as a JSON object")
+		}
+	}
+	registered, err := json.Marshal(c.RegisteredClaims)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(registered, &out); err != nil {
+		return nil, err
+	}
+	if c.SessionID != "" {
+		out["sid"], _ = json.Marshal(c.SessionID)
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes the payload into both the registered and the custom claims.
+func (c *TypedClaims[T]) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.RegisteredClaims); err != nil {
+		return err
+	}
+	var sid struct {
+		SessionID string `json:"sid"`
+	}
+	if err := json.Unmarshal(data, &sid); err != nil {
+		return err
+	}
+	c.SessionID = sid.SessionID
+	return json.Unmarshal(data, &c.Custom)
+}
+
+// MapClaims returns the flattened payload as jwt.MapClaims, e.g. for exgin.JWTAuthOptions.Parser.
+func (c *TypedClaims[T]) MapClaims() (jwt.MapClaims, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	mc := jwt.MapClaims{}
+	if err := json.Unmarshal(data, &mc); err != nil {
+		return nil, err
+	}
+	return mc, nil
+}
+
+// IssuerOptions configures an Issuer. Exactly one of Secret (HS256) or KeySet must be set.
+type IssuerOptions[T any] struct {
+	// Secret signs and verifies with HS256.
+	Secret []byte
+	// KeySet signs with its signing key and verifies by kid.
+	KeySet *KeySet
+	// Issuer is set as iss and, when not empty, required on parse.
+	Issuer string
+	// Audience is set as aud and, when not empty, parse requires the token to contain one of them.
+	Audience []string
+	// TTL is the default token lifetime, 24h if zero.
+	TTL time.Duration
+	// TTLFunc overrides TTL per token, e.g. shorter lifetimes for privileged subjects.
+	// A non-positive result falls back to TTL.
+	TTLFunc func(subject string, custom T) time.Duration
+	// Leeway tolerates clock skew on exp/nbf/iat, 1m if zero.
+	Leeway time.Duration
+	// Now is the clock, time.Now if nil.
+	Now func() time.Time
+}
+
+// Issuer issues and parses tokens with custom claims of type T.
+type Issuer[T any] struct {
+	opts IssuerOptions[T]
+}
+
+// NewIssuer creates an Issuer.
+func NewIssuer[T any](opts IssuerOptions[T]) (*Issuer[T], error) {
+	switch {
+	case opts.KeySet != nil && len(opts.Secret) > 0:
+		return nil, errors.New("jwt: set either Secret or KeySet, not both")
+	case opts.KeySet == nil && len(opts.Secret) == 0:
+		return nil, ErrEmptySecret
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = 24 * time.Hour
+	}
+	if opts.Leeway <= 0 {
+		opts.Leeway = time.Minute
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Issuer[T]{opts: opts}, nil
+}
+
+// Issue signs a token for subject with custom claims.
+func (i *Issuer[T]) Issue(subject string, custom T) (string, error) {
+	t, _, err := i.IssueClaims(&TypedClaims[T]{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: subject},
+		Custom:           custom,
+	})
+	return t, err
+}
+
+// IssueClaims signs claims after filling unset iss, aud, iat, nbf, exp and jti,
+// and returns the claims as signed.
+func (i *Issuer[T]) IssueClaims(claims *TypedClaims[T]) (string, *TypedClaims[T], error) {
+	now := i.opts.Now()
+	rc := &claims.RegisteredClaims
+	if rc.Issuer == "" {
+		rc.Issuer = i.opts.Issuer
+	}
+	if len(rc.Audience) == 0 && len(i.opts.Audience) > 0 {
+		rc.Audience = append(jwt.ClaimStrings(nil), i.opts.Audience...)
+	}
+	if rc.IssuedAt == nil {
+		rc.IssuedAt = jwt.NewNumericDate(now)
+	}
+	if rc.NotBefore == nil {
+		rc.NotBefore = jwt.NewNumericDate(now)
+	}
+	if rc.ExpiresAt == nil {
+		rc.ExpiresAt = jwt.NewNumericDate(now.Add(i.ttl(claims)))
+	}
+	if rc.ID == "" {
+		rc.ID = rand.Text()
+	}
+
+	var (
+		t   string
+		err error
+	)
+	if i.opts.KeySet != nil {
+		t, err = i.opts.KeySet.Sign(claims)
+	} else {
+		t, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(i.opts.Secret)
+		err = errors.Wrap(err, "JWT generate failure")
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return t, claims, nil
+}
+
+func (i *Issuer[T]) ttl(claims *TypedClaims[T]) time.Duration {
+	if i.opts.TTLFunc != nil {
+		if d := i.opts.TTLFunc(claims.Subject, claims.Custom); d > 0 {
+			return d
+		}
+	}
+	return i.opts.TTL
+}
+
+// Parse verifies the token signature, time claims, issuer and audience and returns typed claims.
+// Expired tokens return an error matching ErrExpired.
+func (i *Issuer[T]) Parse(ts string) (*TypedClaims[T], error) {
+	popts := []jwt.ParserOption{
+		jwt.WithLeeway(i.opts.Leeway),
+		jwt.WithTimeFunc(i.opts.Now),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+	}
+	if i.opts.Issuer != "" {
+		popts = append(popts, jwt.WithIssuer(i.opts.Issuer))
+	}
+	if len(i.opts.Audience) > 0 {
+		popts = append(popts, jwt.WithAudience(i.opts.Audience...))
+	}
+	keyfunc := func(*jwt.Token) (any, error) { return i.opts.Secret, nil }
+	if i.opts.KeySet != nil {
+		keyfunc = i.opts.KeySet.Keyfunc
+		popts = append(popts, jwt.WithValidMethods(asymmetricAlgs))
+	} else {
+		popts = append(popts, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	}
+
+	c := &TypedClaims[T]{}
+	token, err := jwt.ParseWithClaims(ts, c, keyfunc, popts...)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, fmt.Errorf("%w: %w", ErrExpired, err)
+		}
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	return c, nil
+}
+
+// ParseMap parses the token and returns the flattened claims, usable as exgin.JWTAuthOptions.Parser.
+func (i *Issuer[T]) ParseMap(ts string) (jwt.MapClaims, error) {
+	c, err := i.Parse(ts)
+	if err != nil {
+		return nil, err
+	}
+	return c.MapClaims()
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exjwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type tenantClaims struct {
+	TenantID string   `json:"tenant_id"`
+	Roles    []string `json:"roles,omitempty"`
+	Scope    string   `json:"scope,omitempty"`
+}
+
+func TestIssuerTypedClaims(t *testing.T) {
+	iss, err := NewIssuer(IssuerOptions[tenantClaims]{
+		Secret:   []byte("issuer-secret"),
+		Issuer:   "auth.example.com",
+		Audience: []string{"api"},
+		TTLFunc: func(_ string, c tenantClaims) time.Duration {
+			for _, r := range c.Roles {
+				if r == "admin" {
+					return time.Hour
+				}
+			}
+			return 0
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := iss.Issue("user-1", tenantClaims{TenantID: "t-1", Roles: []string{"admin"}, Scope: "read"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// custom claims are flattened into the payload
+	payload, _ := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[1])
+	var raw map[string]any
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["tenant_id"] != "t-1" || raw["iss"] != "auth.example.com" || raw["jti"] == "" {
+		t.Fatalf("unexpected payload: %s", payload)
+	}
+
+	c, err := iss.Parse(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Subject != "user-1" || c.Custom.TenantID != "t-1" || c.Custom.Roles[0] != "admin" {
+		t.Fatalf("claims mismatch: %+v", c)
+	}
+	if ttl := c.ExpiresAt.Sub(c.IssuedAt.Time); ttl != time.Hour {
+		t.Fatalf("TTLFunc not applied: %v", ttl)
+	}
+
+	mc, err := iss.ParseMap(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mc["sub"] != "user-1" || mc["scope"] != "read" {
+		t.Fatalf("map claims mismatch: %v", mc)
+	}
+
+	token, _ = iss.Issue("user-2", tenantClaims{TenantID: "t-2"})
+	c, _ = iss.Parse(token)
+	if ttl := c.ExpiresAt.Sub(c.IssuedAt.Time); ttl != 24*time.Hour {
+		t.Fatalf("default TTL: %v", ttl)
+	}
+}
+
+func TestIssuerValidation(t *testing.T) {
+	secret := []byte("issuer-secret")
+	now := time.Now()
+	clock := func() time.Time { return now }
+	iss, _ := NewIssuer(IssuerOptions[tenantClaims]{Secret: secret, Issuer: "a", Audience: []string{"api"}, TTL: time.Minute, Now: clock})
+	other, _ := NewIssuer(IssuerOptions[tenantClaims]{Secret: secret, Issuer: "b", Audience: []string{"api"}})
+	web, _ := NewIssuer(IssuerOptions[tenantClaims]{Secret: secret, Issuer: "a", Audience: []string{"web"}})
+
+	token, _ := iss.Issue("user-1", tenantClaims{})
+	if _, err := other.Parse(token); err == nil {
+		t.Fatal("expected issuer mismatch")
+	}
+	if _, err := web.Parse(token); err == nil {
+		t.Fatal("expected audience mismatch")
+	}
+
+	now = now.Add(3 * time.Minute)
+	if _, err := iss.Parse(token); !errors.Is(err, ErrExpired) {
+		t.Fatalf("expected ErrExpired, got %v", err)
+	}
+
+	// legacy tokens carry no iss
+	legacy, _ := AuthWithSecret("alice", "uuid-1", secret)
+	if _, err := other.Parse(legacy); err == nil {
+		t.Fatal("expected legacy token without iss to be rejected")
+	}
+
+	if _, err := NewIssuer(IssuerOptions[tenantClaims]{}); !errors.Is(err, ErrEmptySecret) {
+		t.Fatalf("expected ErrEmptySecret, got %v", err)
+	}
+}
+
+func TestIssuerWithKeySet(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	k, _ := NewKey("k1", priv)
+	ks, _ := NewKeySet(k)
+	iss, err := NewIssuer(IssuerOptions[map[string]any]{KeySet: ks})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := iss.Issue("svc", map[string]any{"tenant_id": "t-9"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := iss.Parse(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Custom["tenant_id"] != "t-9" {
+		t.Fatalf("custom claims: %v", c.Custom)
+	}
+	hmacIss, _ := NewIssuer(IssuerOptions[map[string]any]{Secret: []byte("x")})
+	hs, _ := hmacIss.Issue("svc", nil)
+	if _, err := iss.Parse(hs); err == nil {
+		t.Fatal("expected HS256 token to be rejected by key set issuer")
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exjwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+
+	"github.com/cockroachdb/errors"
+)
+
+// JWK is a public JSON Web Key (RFC 7517) for RSA, EC and OKP (Ed25519) keys.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set document.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+var b64 = base64.RawURLEncoding
+
+// JWK returns the public part of k. Private material is never included.
+func (k *Key) JWK() (JWK, error) {
+	j := JWK{Kid: k.ID, Use: "sig", Alg: k.Algorithm}
+	switch pub := k.Public.(type) {
+	case *rsa.PublicKey:
+		j.Kty = "RSA"
+		j.N = b64.EncodeToString(pub.N.Bytes())
+		j.E = b64.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		ec, err := pub.ECDH()
+		if err != nil {
+			return JWK{}, errors.Wrapf(err, "kid %s", k.ID)
+		}
+		// uncompressed point: 0x04 || X || Y
+		point := ec.Bytes()[1:]
+		size := len(point) / 2
+		j.Kty = "EC"
+		j.Crv = pub.Curve.Params().Name
+		j.X = b64.EncodeToString(point[:size])
+		j.Y = b64.EncodeToString(point[size:])
+	case ed25519.PublicKey:
+		j.Kty = "OKP"
+		j.Crv = "Ed25519"
+		j.X = b64.EncodeToString(pub)
+	default:
+		return JWK{}, errors.Wrapf(ErrUnsupportedKey, "%T", k.Public)
+	}
+	return j, nil
+}
+
+// JWKS returns the public keys of the set, sorted by kid.
+func (s *KeySet) JWKS() (*JWKS, error) {
+	doc := &JWKS{Keys: []JWK{}}
+	for _, k := range s.Keys() {
+		j, err := k.JWK()
+		if err != nil {
+			return nil, err
+		}
+		doc.Keys = append(doc.Keys, j)
+	}
+	return doc, nil
+}
+
+// PublicKey converts the JWK back to a verification-only Key.
+func (j JWK) PublicKey() (*Key, error) {
+	var key any
+	switch j.Kty {
+	case "RSA":
+		n, err := b64.DecodeString(j.N)
+		if err != nil {
+			return nil, errors.Wrapf(err, "kid %s: n", j.Kid)
+		}
+		e, err := b64.DecodeString(j.E)
+		if err != nil {
+			return nil, errors.Wrapf(err, "kid %s: e", j.Kid)
+		}
+		key = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Wrapf(ErrUnsupportedKey, "kid %s: curve %s", j.Kid, j.Crv)
+		}
+		x, err := b64.DecodeString(j.X)
+		if err != nil {
+			return nil, errors.Wrapf(err, "kid %s: x", j.Kid)
+		}
+		y, err := b64.DecodeString(j.Y)
+		if err != nil {
+			return nil, errors.Wrapf(err, "kid %s: y", j.Kid)
+		}
+		point := append(append([]byte{4}, x...), y...)
+		if key, err = ecdsa.ParseUncompressedPublicKey(curve, point); err != nil {
+			return nil, errors.Wrapf(err, "kid %s", j.Kid)
+		}
+	case "OKP":
+		x, err := b64.DecodeString(j.X)
+		if err != nil || j.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, errors.Wrapf(ErrUnsupportedKey, "kid %s: OKP %s", j.Kid, j.Crv)
+		}
+		key = ed25519.PublicKey(x)
+	default:
+		return nil, errors.Wrapf(ErrUnsupportedKey, "kid %s: kty %s", j.Kid, j.Kty)
+	}
+	k, err := NewKey(j.Kid, key)
+	if err != nil {
+		return nil, err
+	}
+	if j.Alg != "" && j.Alg != k.Algorithm {
+		return nil, errors.Wrapf(ErrAlgMismatch, "kid %s: %s", j.Kid, j.Alg)
+	}
+	return k, nil
+}
+
+// ParseJWKS builds a verification-only KeySet from a JWKS document, for services
+// that verify tokens issued elsewhere. Keys with use other than "sig" are skipped.
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var doc JWKS
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrap(err, "parse jwks")
+	}
+	s := &KeySet{keys: map[string]*Key{}}
+	for _, j := range doc.Keys {
+		if j.Use != "" && j.Use != "sig" {
+			continue
+		}
+		k, err := j.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.Add(k); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
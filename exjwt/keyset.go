@@ -0,0 +1,286 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exjwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// asymmetricAlgs are the algorithms a KeySet accepts; HS* and none are always rejected.
+var asymmetricAlgs = []string{"RS256", "ES256", "ES384", "ES512", "EdDSA"}
+
+var (
+	ErrNoSigningKey   = errors.New("jwt: no signing key")
+	ErrKeyNotFound    = errors.New("jwt: key not found")
+	ErrUnsupportedKey = errors.New("jwt: unsupported key type")
+)
+
+// Key is a single asymmetric key identified by kid.
+// Private is nil for verification-only keys.
+type Key struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	Public    crypto.PublicKey
+}
+
+// NewKey wraps a private or public key and infers the algorithm:
+// RSA -> RS256, ECDSA P-256/P-384/P-521 -> ES256/ES384/ES512, Ed25519 -> EdDSA.
+func NewKey(id string, key any) (*Key, error) {
+	if id == "" {
+		return nil, errors.New("jwt: empty key id")
+	}
+	k := &Key{ID: id}
+	if signer, ok := key.(crypto.Signer); ok {
+		k.Private = signer
+		key = signer.Public()
+	}
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		k.Algorithm = jwt.SigningMethodRS256.Alg()
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			k.Algorithm = jwt.SigningMethodES256.Alg()
+		case elliptic.P384():
+			k.Algorithm = jwt.SigningMethodES384.Alg()
+		case elliptic.P521():
+			k.Algorithm = jwt.SigningMethodES512.Alg()
+		default:
+			return nil, errors.Wrapf(ErrUnsupportedKey, "curve %s", pub.Curve.Params().Name)
+		}
+	case ed25519.PublicKey:
+		k.Algorithm = jwt.SigningMethodEdDSA.Alg()
+	default:
+		return nil, errors.Wrapf(ErrUnsupportedKey, "%T", key)
+	}
+	k.Public = key
+	return k, nil
+}
+
+// ParseKeyPEM parses the first PEM block of data. Supported blocks are
+// PKCS#8/PKCS#1/SEC1 private keys, PKIX/PKCS#1 public keys and certificates.
+func ParseKeyPEM(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("jwt: no PEM block found")
+	}
+	var (
+		key any
+		err error
+	)
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		return nil, errors.Wrapf(ErrUnsupportedKey, "PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse key %s", id)
+	}
+	return NewKey(id, key)
+}
+
+// LoadKeyFile loads a PEM key file. The kid is the file name without extension.
+func LoadKeyFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeyPEM(keyIDFromPath(path), data)
+}
+
+func keyIDFromPath(path string) string {
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// KeySet holds the verification keys and the current signing key.
+// It is safe for concurrent use, so keys can be rotated at runtime.
+type KeySet struct {
+	mu      sync.RWMutex
+	keys    map[string]*Key
+	signing string
+}
+
+// NewKeySet creates a key set. The first key with a private part becomes the signing key.
+func NewKeySet(keys ...*Key) (*KeySet, error) {
+	s := &KeySet{keys: map[string]*Key{}}
+	for _, k := range keys {
+		if err := s.Add(k); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// LoadKeySetDir loads all *.pem and *.key files in dir. Files are sorted by name and
+// the last private key becomes the signing key, so naming keys by date (2025-01.pem,
+// 2025-06.pem) rotates to the newest one while older keys keep verifying.
+func LoadKeySetDir(dir string) (*KeySet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if ext := filepath.Ext(e.Name()); !e.IsDir() && (ext == ".pem" || ext == ".key") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	s := &KeySet{keys: map[string]*Key{}}
+	for _, name := range names {
+		k, err := LoadKeyFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		if err := s.Add(k); err != nil {
+			return nil, err
+		}
+		if k.Private != nil {
+			s.signing = k.ID
+		}
+	}
+	return s, nil
+}
+
+// Add adds or replaces a key. If no signing key is set yet and k has a private part, it becomes the signing key.
+func (s *KeySet) Add(k *Key) error {
+	if k == nil || k.ID == "" || k.Public == nil {
+		return errors.New("jwt: invalid key")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[k.ID] = k
+	if s.signing == "" && k.Private != nil {
+		s.signing = k.ID
+	}
+	return nil
+}
+
+// Remove removes a key, tokens signed by it no longer verify.
+func (s *KeySet) Remove(kid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, kid)
+	if s.signing == kid {
+		s.signing = ""
+	}
+}
+
+// SetSigningKey switches the signing key to kid, which must have a private part.
+func (s *KeySet) SetSigningKey(kid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k, ok := s.keys[kid]
+	if !ok {
+		return errors.Wrapf(ErrKeyNotFound, "kid %s", kid)
+	}
+	if k.Private == nil {
+		return errors.Wrapf(ErrNoSigningKey, "kid %s has no private key", kid)
+	}
+	s.signing = kid
+	return nil
+}
+
+// Key returns the key with the given kid.
+func (s *KeySet) Key(kid string) (*Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	k, ok := s.keys[kid]
+	return k, ok
+}
+
+// Keys returns all keys sorted by kid.
+func (s *KeySet) Keys() []*Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]*Key, 0, len(s.keys))
+	for _, k := range s.keys {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys
+}
+
+// Sign signs claims with the current signing key and sets the kid header.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	s.mu.RLock()
+	k := s.keys[s.signing]
+	s.mu.RUnlock()
+	if k == nil {
+		return "", ErrNoSigningKey
+	}
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(k.Algorithm), claims)
+	token.Header["kid"] = k.ID
+	t, err := token.SignedString(k.Private)
+	if err != nil {
+		return "", errors.Wrap(err, "JWT generate failure")
+	}
+	return t, nil
+}
+
+// Keyfunc resolves the verification key by the kid header and checks the algorithm.
+// A token without kid is accepted only when the set holds exactly one key.
+func (s *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	kid, _ := token.Header["kid"].(string)
+	k, ok := s.keys[kid]
+	if kid == "" && len(s.keys) == 1 {
+		for _, only := range s.keys {
+			k, ok = only, true
+		}
+	}
+	if !ok {
+		return nil, errors.Wrapf(ErrKeyNotFound, "kid %q", kid)
+	}
+	if token.Method.Alg() != k.Algorithm {
+		return nil, ErrAlgMismatch
+	}
+	return k.Public, nil
+}
+
+// AuthWithKeySet signs a JWT with the signing key of ks, using the same claims and TTL policy as AuthWithSecret.
+func AuthWithKeySet(username string, uuid string, ks *KeySet) (string, error) {
+	return ks.Sign(newClaims(username, uuid))
+}
+
+// ParseWithKeySet parses and validates a token against the keys of ks and
+// returns every claim of the verified token, including custom ones such as roles.
+func ParseWithKeySet(ts string, ks *KeySet) (jwt.MapClaims, error) {
+	return parseMapClaims(ts, ks.Keyfunc, asymmetricAlgs)
+}
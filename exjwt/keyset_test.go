@@ -0,0 +1,204 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exjwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func genKeys(t *testing.T) map[string]any {
+	t.Helper()
+	rk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ek, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edk, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]any{"RS256": rk, "ES256": ek, "EdDSA": edk}
+}
+
+func TestKeySetSignAndParse(t *testing.T) {
+	for alg, priv := range genKeys(t) {
+		k, err := NewKey("k-"+alg, priv)
+		if err != nil {
+			t.Fatalf("%s: %v", alg, err)
+		}
+		if k.Algorithm != alg {
+			t.Fatalf("algorithm: got %s want %s", k.Algorithm, alg)
+		}
+		ks, err := NewKeySet(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, err := AuthWithKeySet("alice", "uuid-1", ks)
+		if err != nil {
+			t.Fatalf("%s sign: %v", alg, err)
+		}
+		claims, err := ParseWithKeySet(token, ks)
+		if err != nil {
+			t.Fatalf("%s parse: %v", alg, err)
+		}
+		if claims["username"] != "alice" || claims["sub"] != "uuid-1" {
+			t.Fatalf("%s claims mismatch: %v", alg, claims)
+		}
+		// custom claims must survive verification
+		custom, err := ks.Sign(jwt.MapClaims{"sub": "u1", "roles": []string{"admin"}, "exp": time.Now().Add(time.Hour).Unix()})
+		if err != nil {
+			t.Fatalf("%s sign custom: %v", alg, err)
+		}
+		claims, err = ParseWithKeySet(custom, ks)
+		if err != nil {
+			t.Fatalf("%s parse custom: %v", alg, err)
+		}
+		if roles, _ := claims["roles"].([]any); len(roles) != 1 || roles[0] != "admin" {
+			t.Fatalf("%s roles lost: %v", alg, claims)
+		}
+		// HS256 tokens must not be accepted by an asymmetric key set
+		hs, _ := AuthWithSecret("alice", "uuid-1", []byte("secret"))
+		if _, err := ParseWithKeySet(hs, ks); err == nil {
+			t.Fatalf("%s: expected HS256 token to be rejected", alg)
+		}
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	keys := genKeys(t)
+	oldKey, _ := NewKey("2025-01", keys["ES256"])
+	newKey, _ := NewKey("2025-06", keys["EdDSA"])
+	ks, _ := NewKeySet(oldKey)
+
+	oldToken, err := AuthWithKeySet("alice", "uuid-1", ks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Add(newKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.SetSigningKey("2025-06"); err != nil {
+		t.Fatal(err)
+	}
+	newToken, _ := AuthWithKeySet("bob", "uuid-2", ks)
+	for _, tok := range []string{oldToken, newToken} {
+		if _, err := ParseWithKeySet(tok, ks); err != nil {
+			t.Fatalf("parse during rotation: %v", err)
+		}
+	}
+
+	ks.Remove("2025-01")
+	if _, err := ParseWithKeySet(oldToken, ks); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound after removal, got %v", err)
+	}
+	if _, err := ParseWithKeySet(newToken, ks); err != nil {
+		t.Fatalf("new token: %v", err)
+	}
+	if err := ks.SetSigningKey("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("expected ErrKeyNotFound, got %v", err)
+	}
+}
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadKeySetDir(t *testing.T) {
+	keys := genKeys(t)
+	dir := t.TempDir()
+	rsaDER := x509.MarshalPKCS1PrivateKey(keys["RS256"].(*rsa.PrivateKey))
+	writePEM(t, filepath.Join(dir, "2025-01.pem"), "RSA PRIVATE KEY", rsaDER)
+	ecDER, _ := x509.MarshalECPrivateKey(keys["ES256"].(*ecdsa.PrivateKey))
+	writePEM(t, filepath.Join(dir, "2025-06.key"), "EC PRIVATE KEY", ecDER)
+	edPub, _ := x509.MarshalPKIXPublicKey(keys["EdDSA"].(ed25519.PrivateKey).Public())
+	writePEM(t, filepath.Join(dir, "2025-09.pem"), "PUBLIC KEY", edPub)
+	_ = os.WriteFile(filepath.Join(dir, "README"), []byte("ignored"), 0o600)
+
+	ks, err := LoadKeySetDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(ks.Keys()); n != 3 {
+		t.Fatalf("keys: got %d want 3", n)
+	}
+	token, err := AuthWithKeySet("alice", "uuid-1", ks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the last private key by name signs, the public-only key is verification only
+	if k, _ := ks.Key("2025-06"); k.Algorithm != "ES256" {
+		t.Fatalf("unexpected key: %+v", k)
+	}
+	if _, err := ParseWithKeySet(token, ks); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.SetSigningKey("2025-09"); !errors.Is(err, ErrNoSigningKey) {
+		t.Fatalf("expected ErrNoSigningKey, got %v", err)
+	}
+}
+
+func TestJWKSRoundTrip(t *testing.T) {
+	var keys []*Key
+	for alg, priv := range genKeys(t) {
+		k, _ := NewKey("k-"+alg, priv)
+		keys = append(keys, k)
+	}
+	issuer, _ := NewKeySet(keys...)
+	doc, err := issuer.JWKS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(doc)
+	var raw struct {
+		Keys []map[string]any `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil || len(raw.Keys) != 3 {
+		t.Fatalf("bad JWKS: %s", data)
+	}
+	for _, k := range raw.Keys {
+		if _, ok := k["d"]; ok {
+			t.Fatalf("JWKS leaks private material: %v", k)
+		}
+	}
+
+	verifier, err := ParseJWKS(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AuthWithKeySet("alice", "uuid-1", verifier); !errors.Is(err, ErrNoSigningKey) {
+		t.Fatalf("expected ErrNoSigningKey, got %v", err)
+	}
+	for _, k := range keys {
+		if err := issuer.SetSigningKey(k.ID); err != nil {
+			t.Fatal(err)
+		}
+		token, _ := AuthWithKeySet("alice", "uuid-1", issuer)
+		if _, err := ParseWithKeySet(token, verifier); err != nil {
+			t.Fatalf("%s: %v", k.Algorithm, err)
+		}
+	}
+}
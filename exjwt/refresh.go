@@ -0,0 +1,236 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exjwt
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"time"
+
+	"github.com/ergoapi/util/exhash"
+
+	"github.com/cockroachdb/errors"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrRefreshInvalid = errors.New("jwt: refresh token invalid")
+	ErrRefreshReused  = errors.New("jwt: refresh token reused, session revoked")
+	ErrTokenRevoked   = errors.New("jwt: token revoked")
+)
+
+// RefreshRecord is the stored state of one refresh token. Only the SHA-256 hash of the
+// token is persisted, so a leaked store does not leak usable tokens.
+type RefreshRecord struct {
+	Hash      string          `json:"hash"`
+	Family    string          `json:"family"`
+	Subject   string          `json:"subject"`
+	Custom    json.RawMessage `json:"custom,omitempty"`
+	AccessJTI string          `json:"access_jti"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	Used      bool            `json:"used"`
+}
+
+// RefreshStore persists refresh tokens and revocations.
+type RefreshStore interface {
+	// Save stores rec, keyed by rec.Hash.
+	Save(ctx context.Context, rec *RefreshRecord) error
+	// Get returns the record for hash, or ErrRefreshInvalid if it does not exist or has expired.
+	Get(ctx context.Context, hash string) (*RefreshRecord, error)
+	// MarkUsed marks the record as used and reports whether this call did it,
+	// false means it was already used.
+	MarkUsed(ctx context.Context, hash string) (bool, error)
+	// Revoke revokes id (a jti or a family) until the given time.
+	Revoke(ctx context.Context, id string, until time.Time) error
+	// Revoked reports whether id is revoked.
+	Revoked(ctx context.Context, id string) (bool, error)
+}
+
+// TokenPair is the result of a login or refresh.
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// TokenManagerOptions configures a TokenManager.
+type TokenManagerOptions[T any] struct {
+	// RefreshTTL is the lifetime of each refresh token, 30 days if zero. Rotation slides it.
+	RefreshTTL time.Duration
+	// Reload refreshes custom claims on each refresh, e.g. to pick up role changes.
+	// Returning an error aborts the refresh.
+	Reload func(ctx context.Context, subject string, custom T) (T, error)
+}
+
+// TokenManager pairs short-lived access tokens from an Issuer with opaque refresh tokens.
+// Each refresh rotates the refresh token; presenting an already used one is treated as theft
+// and revokes the whole family (the session), including access tokens issued from it.
+type TokenManager[T any] struct {
+	issuer *Issuer[T]
+	store  RefreshStore
+	opts   TokenManagerOptions[T]
+}
+
+// NewTokenManager creates a TokenManager.
+func NewTokenManager[T any](issuer *Issuer[T], store RefreshStore, opts TokenManagerOptions[T]) *TokenManager[T] {
+	if opts.RefreshTTL <= 0 {
+		opts.RefreshTTL = 30 * 24 * time.Hour
+	}
+	return &TokenManager[T]{issuer: issuer, store: store, opts: opts}
+}
+
+// Login starts a new session and returns the first token pair.
+func (m *TokenManager[T]) Login(ctx context.Context, subject string, custom T) (*TokenPair, error) {
+	return m.issue(ctx, rand.Text(), subject, custom)
+}
+
+func (m *TokenManager[T]) issue(ctx context.Context, family, subject string, custom T) (*TokenPair, error) {
+	access, claims, err := m.issuer.IssueClaims(&TypedClaims[T]{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: subject},
+		SessionID:        family,
+		Custom:           custom,
+	})
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(custom)
+	if err != nil {
+		return nil, err
+	}
+	refresh := rand.Text() + rand.Text()
+	rec := &RefreshRecord{
+		Hash:      exhash.GenSha256(refresh),
+		Family:    family,
+		Subject:   subject,
+		Custom:    raw,
+		AccessJTI: claims.ID,
+		ExpiresAt: m.issuer.opts.Now().Add(m.opts.RefreshTTL),
+	}
+	if err := m.store.Save(ctx, rec); err != nil {
+		return nil, errors.Wrap(err, "save refresh token")
+	}
+	return &TokenPair{
+		AccessToken:      access,
+		TokenType:        "Bearer",
+		ExpiresAt:        claims.ExpiresAt.Time,
+		RefreshToken:     refresh,
+		RefreshExpiresAt: rec.ExpiresAt,
+	}, nil
+}
+
+// Refresh exchanges a refresh token for a new pair. The presented token is consumed.
+func (m *TokenManager[T]) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	rec, err := m.store.Get(ctx, exhash.GenSha256(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if revoked, err := m.store.Revoked(ctx, familyKey(rec.Family)); err != nil {
+		return nil, err
+	} else if revoked {
+		return nil, ErrTokenRevoked
+	}
+	if !m.issuer.opts.Now().Before(rec.ExpiresAt) {
+		return nil, ErrRefreshInvalid
+	}
+	first, err := m.store.MarkUsed(ctx, rec.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if !first {
+		if err := m.revokeFamily(ctx, rec.Family); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshReused
+	}
+
+	var custom T
+	if len(rec.Custom) > 0 {
+		if err := json.Unmarshal(rec.Custom, &custom); err != nil {
+			return nil, errors.Wrap(err, "decode refresh claims")
+		}
+	}
+	if m.opts.Reload != nil {
+		if custom, err = m.opts.Reload(ctx, rec.Subject, custom); err != nil {
+			return nil, err
+		}
+	}
+	return m.issue(ctx, rec.Family, rec.Subject, custom)
+}
+
+// Logout revokes the session the refresh token belongs to. Unknown tokens are ignored.
+func (m *TokenManager[T]) Logout(ctx context.Context, refreshToken string) error {
+	rec, err := m.store.Get(ctx, exhash.GenSha256(refreshToken))
+	if errors.Is(err, ErrRefreshInvalid) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return m.revokeFamily(ctx, rec.Family)
+}
+
+// RevokeSession revokes every refresh and access token of the session (sid claim).
+func (m *TokenManager[T]) RevokeSession(ctx context.Context, sid string) error {
+	return m.revokeFamily(ctx, sid)
+}
+
+// Revoke revokes a single access token by jti until its expiry.
+func (m *TokenManager[T]) Revoke(ctx context.Context, jti string, exp time.Time) error {
+	return m.store.Revoke(ctx, jtiKey(jti), exp.Add(m.issuer.opts.Leeway))
+}
+
+func (m *TokenManager[T]) revokeFamily(ctx context.Context, family string) error {
+	// refresh tokens of the family expire within RefreshTTL, access tokens earlier
+	return m.store.Revoke(ctx, familyKey(family), m.issuer.opts.Now().Add(m.opts.RefreshTTL))
+}
+
+// Parse verifies an access token like Issuer.Parse and additionally rejects revoked
+// tokens and tokens of revoked sessions with ErrTokenRevoked.
+func (m *TokenManager[T]) Parse(ctx context.Context, ts string) (*TypedClaims[T], error) {
+	c, err := m.issuer.Parse(ts)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range []string{jtiKey(c.ID), familyKey(c.SessionID)} {
+		if id == "" {
+			continue
+		}
+		revoked, err := m.store.Revoked(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+	return c, nil
+}
+
+// ParseMap is Parse returning flattened claims, usable as exgin.JWTAuthOptions.Parser.
+func (m *TokenManager[T]) ParseMap(ts string) (jwt.MapClaims, error) {
+	c, err := m.Parse(context.Background(), ts)
+	if err != nil {
+		return nil, err
+	}
+	return c.MapClaims()
+}
+
+func jtiKey(jti string) string {
+	if jti == "" {
+		return ""
+	}
+	return "jti:" + jti
+}
+
+func familyKey(family string) string {
+	if family == "" {
+		return ""
+	}
+	return "sid:" + family
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exjwt
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ergoapi/util/cache"
+
+	"github.com/cockroachdb/errors"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+// CacheRefreshStore stores refresh tokens and revocations in a cache.Cache.
+// MarkUsed is a read-then-write, so two instances refreshing the same token at the same
+// instant may both succeed; use SQLiteRefreshStore when that matters.
+type CacheRefreshStore struct {
+	Cache  cache.Cache
+	Prefix string
+}
+
+var _ RefreshStore = (*CacheRefreshStore)(nil)
+
+// NewCacheRefreshStore creates a cache-backed store, prefix defaults to "exjwt:".
+func NewCacheRefreshStore(c cache.Cache, prefix string) *CacheRefreshStore {
+	if prefix == "" {
+		prefix = "exjwt:"
+	}
+	return &CacheRefreshStore{Cache: c, Prefix: prefix}
+}
+
+func (s *CacheRefreshStore) tokenKey(hash string) string {
+	return s.Prefix + "refresh:" + hash
+}
+
+func (s *CacheRefreshStore) Save(ctx context.Context, rec *RefreshRecord) error {
+	ttl := time.Until(rec.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return cache.SetJSON(ctx, s.Cache, s.tokenKey(rec.Hash), rec, cache.WithExpiration(ttl))
+}
+
+func (s *CacheRefreshStore) Get(ctx context.Context, hash string) (*RefreshRecord, error) {
+	rec := &RefreshRecord{}
+	if err := cache.GetJSON(ctx, s.Cache, s.tokenKey(hash), rec); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRefreshInvalid, err)
+	}
+	return rec, nil
+}
+
+func (s *CacheRefreshStore) MarkUsed(ctx context.Context, hash string) (bool, error) {
+	rec, err := s.Get(ctx, hash)
+	if err != nil {
+		return false, err
+	}
+	if rec.Used {
+		return false, nil
+	}
+	rec.Used = true
+	return true, s.Save(ctx, rec)
+}
+
+func (s *CacheRefreshStore) Revoke(ctx context.Context, id string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.Cache.Set(ctx, s.Prefix+"revoked:"+id, "1", cache.WithExpiration(ttl))
+}
+
+func (s *CacheRefreshStore) Revoked(ctx context.Context, id string) (bool, error) {
+	// only a miss means not revoked; backend failures are returned so callers fail closed
+	_, err := s.Cache.Get(ctx, s.Prefix+"revoked:"+id)
+	if errors.Is(err, cache.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// refreshRow is a row of exjwt_refresh_tokens.
+type refreshRow struct {
+	Hash      string `gorm:"primaryKey;size:64"`
+	Family    string `gorm:"index;size:64"`
+	Subject   string `gorm:"index;size:255"`
+	Custom    []byte
+	AccessJTI string    `gorm:"size:64"`
+	ExpiresAt time.Time `gorm:"index"`
+	Used      bool
+}
+
+func (refreshRow) TableName() string {
+	return "exjwt_refresh_tokens"
+}
+
+// revocationRow is a row of exjwt_revocations, ID is "jti:<jti>" or "sid:<family>".
+type revocationRow struct {
+	ID    string    `gorm:"primaryKey;size:128"`
+	Until time.Time `gorm:"index"`
+}
+
+func (revocationRow) TableName() string {
+	return "exjwt_revocations"
+}
+
+// SQLiteRefreshStore stores refresh tokens and revocations in SQLite.
+// MarkUsed is a conditional update, so concurrent refreshes of one token cannot both win.
+type SQLiteRefreshStore struct {
+	DB *gorm.DB
+}
+
+var _ RefreshStore = (*SQLiteRefreshStore)(nil)
+
+// NewSQLiteRefreshStore opens dsn and migrates the tables.
+func NewSQLiteRefreshStore(dsn string) (*SQLiteRefreshStore, error) {
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		return nil, errors.Wrap(err, "exjwt: open sqlite")
+	}
+	if err := db.AutoMigrate(&refreshRow{}, &revocationRow{}); err != nil {
+		return nil, errors.Wrap(err, "exjwt: migrate sqlite")
+	}
+	return &SQLiteRefreshStore{DB: db}, nil
+}
+
+func (s *SQLiteRefreshStore) Save(ctx context.Context, rec *RefreshRecord) error {
+	row := refreshRow{
+		Hash:      rec.Hash,
+		Family:    rec.Family,
+		Subject:   rec.Subject,
+		Custom:    rec.Custom,
+		AccessJTI: rec.AccessJTI,
+		ExpiresAt: rec.ExpiresAt,
+		Used:      rec.Used,
+	}
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&row).Error
+}
+
+func (s *SQLiteRefreshStore) Get(ctx context.Context, hash string) (*RefreshRecord, error) {
+	var row refreshRow
+	err := s.DB.WithContext(ctx).Where("hash = ? AND expires_at > ?", hash, time.Now()).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRefreshInvalid
+	} else if err != nil {
+		return nil, err
+	}
+	return &RefreshRecord{
+		Hash:      row.Hash,
+		Family:    row.Family,
+		Subject:   row.Subject,
+		Custom:    row.Custom,
+		AccessJTI: row.AccessJTI,
+		ExpiresAt: row.ExpiresAt,
+		Used:      row.Used,
+	}, nil
+}
+
+func (s *SQLiteRefreshStore) MarkUsed(ctx context.Context, hash string) (bool, error) {
+	res := s.DB.WithContext(ctx).Model(&refreshRow{}).Where("hash = ? AND used = ?", hash, false).Update("used", true)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (s *SQLiteRefreshStore) Revoke(ctx context.Context, id string, until time.Time) error {
+	row := revocationRow{ID: id, Until: until}
+	return s.DB.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&row).Error
+}
+
+func (s *SQLiteRefreshStore) Revoked(ctx context.Context, id string) (bool, error) {
+	var n int64
+	err := s.DB.WithContext(ctx).Model(&revocationRow{}).Where("id = ? AND until > ?", id, time.Now()).Count(&n).Error
+	return n > 0, err
+}
+
+// Cleanup deletes expired refresh tokens and revocations.
+func (s *SQLiteRefreshStore) Cleanup(ctx context.Context) error {
+	now := time.Now()
+	db := s.DB.WithContext(ctx)
+	if err := db.Where("expires_at <= ?", now).Delete(&refreshRow{}).Error; err != nil {
+		return err
+	}
+	return db.Where("until <= ?", now).Delete(&revocationRow{}).Error
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package exjwt
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ergoapi/util/cache"
+)
+
+func refreshStores(t *testing.T) map[string]RefreshStore {
+	t.Helper()
+	sqlStore, err := NewSQLiteRefreshStore(filepath.Join(t.TempDir(), "tokens.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]RefreshStore{
+		"cache":  NewCacheRefreshStore(cache.NewGoCache(), ""),
+		"sqlite": sqlStore,
+	}
+}
+
+func newTestManager(t *testing.T, store RefreshStore) *TokenManager[tenantClaims] {
+	t.Helper()
+	iss, err := NewIssuer(IssuerOptions[tenantClaims]{Secret: []byte("refresh-secret"), TTL: 15 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewTokenManager(iss, store, TokenManagerOptions[tenantClaims]{
+		Reload: func(_ context.Context, _ string, c tenantClaims) (tenantClaims, error) {
+			c.Scope = "reloaded"
+			return c, nil
+		},
+	})
+}
+
+func TestRefreshRotationAndReuse(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range refreshStores(t) {
+		t.Run(name, func(t *testing.T) {
+			m := newTestManager(t, store)
+			first, err := m.Login(ctx, "user-1", tenantClaims{TenantID: "t-1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first.RefreshToken == "" || first.TokenType != "Bearer" {
+				t.Fatalf("unexpected pair: %+v", first)
+			}
+			c, err := m.Parse(ctx, first.AccessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.SessionID == "" || c.Custom.TenantID != "t-1" {
+				t.Fatalf("claims: %+v", c)
+			}
+
+			second, err := m.Refresh(ctx, first.RefreshToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if second.RefreshToken == first.RefreshToken {
+				t.Fatal("refresh token not rotated")
+			}
+			c2, err := m.Parse(ctx, second.AccessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c2.SessionID != c.SessionID || c2.Custom.TenantID != "t-1" || c2.Custom.Scope != "reloaded" {
+				t.Fatalf("refreshed claims: %+v", c2)
+			}
+
+			// replaying the consumed token revokes the whole family
+			if _, err := m.Refresh(ctx, first.RefreshToken); !errors.Is(err, ErrRefreshReused) {
+				t.Fatalf("expected ErrRefreshReused, got %v", err)
+			}
+			if _, err := m.Refresh(ctx, second.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+				t.Fatalf("expected ErrTokenRevoked, got %v", err)
+			}
+			if _, err := m.Parse(ctx, second.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+				t.Fatalf("expected access token revoked, got %v", err)
+			}
+
+			if _, err := m.Refresh(ctx, "unknown"); !errors.Is(err, ErrRefreshInvalid) {
+				t.Fatalf("expected ErrRefreshInvalid, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLogoutAndRevoke(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range refreshStores(t) {
+		t.Run(name, func(t *testing.T) {
+			m := newTestManager(t, store)
+			a, _ := m.Login(ctx, "user-1", tenantClaims{})
+			b, _ := m.Login(ctx, "user-1", tenantClaims{})
+
+			if err := m.Logout(ctx, a.RefreshToken); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := m.Parse(ctx, a.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+				t.Fatalf("expected revoked after logout, got %v", err)
+			}
+			if _, err := m.Refresh(ctx, a.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+				t.Fatalf("expected ErrTokenRevoked, got %v", err)
+			}
+			// other sessions are unaffected
+			cb, err := m.Parse(ctx, b.AccessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := m.Revoke(ctx, cb.ID, cb.ExpiresAt.Time); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := m.Parse(ctx, b.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+				t.Fatalf("expected revoked by jti, got %v", err)
+			}
+			if _, err := m.Refresh(ctx, b.RefreshToken); err != nil {
+				t.Fatalf("refresh after jti revoke: %v", err)
+			}
+			if err := m.Logout(ctx, "unknown"); err != nil {
+				t.Fatalf("logout with unknown token: %v", err)
+			}
+		})
+	}
+}
+
+// downCache fails every read, like a cache backend that is unreachable.
+type downCache struct {
+	cache.Cache
+}
+
+var errCacheDown = errors.New("cache down")
+
+func (downCache) Get(context.Context, string) (any, error) {
+	return nil, errCacheDown
+}
+
+func TestCacheRefreshStoreRevokedFailsClosed(t *testing.T) {
+	ctx := context.Background()
+	store := NewCacheRefreshStore(cache.NewGoCache(), "")
+	if revoked, err := store.Revoked(ctx, "jti-1"); err != nil || revoked {
+		t.Fatalf("miss: revoked=%v err=%v", revoked, err)
+	}
+	if err := store.Revoke(ctx, "jti-1", time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if revoked, err := store.Revoked(ctx, "jti-1"); err != nil || !revoked {
+		t.Fatalf("revoked: revoked=%v err=%v", revoked, err)
+	}
+
+	down := NewCacheRefreshStore(downCache{cache.NewGoCache()}, "")
+	if _, err := down.Revoked(ctx, "jti-1"); !errors.Is(err, errCacheDown) {
+		t.Fatalf("expected backend error, got %v", err)
+	}
+}
@@ -62,6 +62,19 @@ func NewClient(cc *ClientConfig) (*Client, error) {
 	}, nil
 }
 
+// Ping checks the Kubernetes API server is reachable, used by health checks.
+// The /version request is bound to ctx, so a cancelled check does not leave a request in flight.
+func (c *Client) Ping(ctx context.Context) error {
+	discovery := c.Clientset.Discovery()
+	rc := discovery.RESTClient()
+	if rc == nil {
+		// fake clientsets have no REST client
+		_, err := discovery.ServerVersion()
+		return err
+	}
+	return rc.Get().AbsPath("/version").Do(ctx).Error()
+}
+
 func (c *Client) CreateSecret(ctx context.Context, namespace string, secret *corev1.Secret, opts metav1.CreateOptions) (*corev1.Secret, error) {
 	return c.Clientset.CoreV1().Secrets(namespace).Create(ctx, secret, opts)
 }
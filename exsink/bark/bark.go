@@ -9,6 +9,7 @@ package bark
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -63,6 +64,11 @@ func (b *Bark) api() string {
 }
 
 func (b *Bark) SendEvent(c Core) error {
+	return b.SendEventWithContext(context.Background(), c)
+}
+
+// SendEventWithContext 发送通知, 并将 ctx 中的 trace 传递给 bark 服务
+func (b *Bark) SendEventWithContext(ctx context.Context, c Core) error {
 	if len(c.Title) > 0 {
 		b.Title = c.Title
 	}
@@ -82,7 +88,12 @@ func (b *Bark) SendEvent(c Core) error {
 	if err != nil {
 		return err
 	}
-	resp, err := b.client.Post(b.api(), "application/json", bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.api(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := b.client.Do(req)
 	if err != nil {
 		return err
 	}
@@ -93,6 +104,8 @@ func (b *Bark) SendEvent(c Core) error {
 	return nil
 }
 
+// NewBark 创建 Bark 客户端, httpClient 为 nil 时使用 http.DefaultClient
+// 需要传递 trace 时可传入 exhttp.WithTracing 包装后的 client
 func NewBark(url, device string, httpClient *http.Client) (*Bark, error) {
 	if httpClient == nil {
 		httpClient = http.DefaultClient
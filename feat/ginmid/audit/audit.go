@@ -0,0 +1,227 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+// Package audit provides an audit trail middleware for mutating API calls.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/ergoapi/util/exgin"
+	"github.com/ergoapi/util/exid"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDenied  = "denied"
+
+	contextKey = "ex-audit"
+)
+
+// Change 单个字段的变更
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Record 审计记录
+type Record struct {
+	ID         string            `json:"id"`
+	Time       time.Time         `json:"time"`
+	Actor      string            `json:"actor"`
+	ActorName  string            `json:"actor_name,omitempty"`
+	Method     string            `json:"method"`
+	Route      string            `json:"route"`
+	Path       string            `json:"path"`
+	ResourceID string            `json:"resource_id,omitempty"`
+	ClientIP   string            `json:"client_ip"`
+	UserAgent  string            `json:"user_agent,omitempty"`
+	TraceID    string            `json:"trace_id,omitempty"`
+	Status     int               `json:"status"`
+	Outcome    string            `json:"outcome"`
+	Error      string            `json:"error,omitempty"`
+	Diff       map[string]Change `json:"diff,omitempty"`
+	Extra      map[string]any    `json:"extra,omitempty"`
+}
+
+// Sink 审计记录的存储, 只追加写入
+type Sink interface {
+	Write(ctx context.Context, record *Record) error
+}
+
+// Options Middleware 配置
+type Options struct {
+	Sink Sink
+	// Methods 需要审计的方法, 默认 POST, PUT, PATCH, DELETE
+	Methods []string
+	// ResourceParam 资源 ID 所在的路径参数, 默认 id
+	ResourceParam string
+	// Skip 返回 true 时不审计
+	Skip func(c *gin.Context) bool
+}
+
+type auditState struct {
+	resourceID string
+	before     any
+	after      any
+	hasBefore  bool
+	hasAfter   bool
+	extra      map[string]any
+}
+
+func state(c *gin.Context) *auditState {
+	if v, ok := c.Get(contextKey); ok {
+		if s, ok := v.(*auditState); ok {
+			return s
+		}
+	}
+	s := &auditState{}
+	c.Set(contextKey, s)
+	return s
+}
+
+// SetResourceID 覆盖从路径参数中解析出的资源 ID
+func SetResourceID(c *gin.Context, id string) {
+	state(c).resourceID = id
+}
+
+// SetBefore 记录变更前的对象, 与 SetAfter 一起生成字段级 diff
+func SetBefore(c *gin.Context, v any) {
+	s := state(c)
+	s.before, s.hasBefore = v, true
+}
+
+// SetAfter 记录变更后的对象
+func SetAfter(c *gin.Context, v any) {
+	s := state(c)
+	s.after, s.hasAfter = v, true
+}
+
+// SetExtra 附加自定义字段
+func SetExtra(c *gin.Context, key string, value any) {
+	s := state(c)
+	if s.extra == nil {
+		s.extra = map[string]any{}
+	}
+	s.extra[key] = value
+}
+
+// Middleware 记录写操作的审计日志, 写入失败只记录错误不影响请求
+// 需放在 exgin.JWTAuth 之后才能获取调用者身份
+func Middleware(opts *Options) gin.HandlerFunc {
+	if opts == nil || opts.Sink == nil {
+		panic("audit: sink is required")
+	}
+	if len(opts.Methods) == 0 {
+		opts.Methods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+	if opts.ResourceParam == "" {
+		opts.ResourceParam = "id"
+	}
+	methods := make(map[string]struct{}, len(opts.Methods))
+	for _, m := range opts.Methods {
+		methods[m] = struct{}{}
+	}
+	return func(c *gin.Context) {
+		if _, ok := methods[c.Request.Method]; !ok || (opts.Skip != nil && opts.Skip(c)) {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		c.Next()
+
+		record := newRecord(c, start, opts.ResourceParam)
+		if err := opts.Sink.Write(context.WithoutCancel(c.Request.Context()), record); err != nil {
+			logrus.Errorf("audit: write record %s failed: %v", record.ID, err)
+		}
+	}
+}
+
+func newRecord(c *gin.Context, start time.Time, resourceParam string) *Record {
+	s := state(c)
+	status := c.Writer.Status()
+	r := &Record{
+		ID:         exid.GenUUID(),
+		Time:       start,
+		Method:     c.Request.Method,
+		Route:      c.FullPath(),
+		Path:       c.Request.URL.Path,
+		ResourceID: c.Param(resourceParam),
+		ClientIP:   c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		TraceID:    c.Writer.Header().Get("X-Trace-Id"),
+		Status:     status,
+		Outcome:    outcome(status),
+		Extra:      s.extra,
+	}
+	if s.resourceID != "" {
+		r.ResourceID = s.resourceID
+	}
+	if claims, ok := exgin.GetAuthClaims(c); ok {
+		r.Actor = claims.Subject
+		r.ActorName = claims.Username
+	}
+	if len(c.Errors) > 0 {
+		r.Error = c.Errors.String()
+	}
+	if s.hasBefore || s.hasAfter {
+		r.Diff = Diff(s.before, s.after)
+	}
+	return r
+}
+
+func outcome(status int) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return OutcomeDenied
+	case status >= http.StatusBadRequest:
+		return OutcomeFailure
+	default:
+		return OutcomeSuccess
+	}
+}
+
+// Diff 按 JSON 字段比较两个对象, 返回发生变化的顶层字段
+// before 为 nil 表示创建, after 为 nil 表示删除
+func Diff(before, after any) map[string]Change {
+	b, a := toMap(before), toMap(after)
+	diff := map[string]Change{}
+	for k, bv := range b {
+		av, ok := a[k]
+		if !ok || !reflect.DeepEqual(bv, av) {
+			diff[k] = Change{Before: bv, After: av}
+		}
+	}
+	for k, av := range a {
+		if _, ok := b[k]; !ok {
+			diff[k] = Change{After: av}
+		}
+	}
+	return diff
+}
+
+func toMap(v any) map[string]any {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	m := map[string]any{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return map[string]any{"value": v}
+	}
+	return m
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/ergoapi/util/exgin"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type project struct {
+	Name  string `json:"name"`
+	Owner string `json:"owner"`
+}
+
+func TestMiddlewareSQLite(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sink, err := NewSQLiteSink(filepath.Join(t.TempDir(), "audit.db"))
+	require.NoError(t, err)
+
+	r := gin.New()
+	r.Use(exgin.JWTAuth(&exgin.JWTAuthOptions{
+		Parser: func(token string) (jwt.MapClaims, error) {
+			return jwt.MapClaims{"sub": token, "username": "alice"}, nil
+		},
+	}))
+	r.Use(Middleware(&Options{Sink: sink}))
+	r.PUT("/projects/:id", func(c *gin.Context) {
+		SetBefore(c, project{Name: "old", Owner: "alice"})
+		SetAfter(c, project{Name: "new", Owner: "alice"})
+		// 响应头由 handler 控制, 不能作为审计 IP 的来源
+		c.Header("X-Forwarded-For", "203.0.113.9")
+		exgin.SucessResponse(c, nil)
+	})
+	r.GET("/projects/:id", func(c *gin.Context) { exgin.SucessResponse(c, nil) })
+	r.DELETE("/projects/:id", func(c *gin.Context) { exgin.GinsAbort(c, http.StatusForbidden, "no") })
+
+	for _, method := range []string{http.MethodPut, http.MethodGet, http.MethodDelete} {
+		req := httptest.NewRequest(method, "/projects/42", nil)
+		req.Header.Set("Authorization", "Bearer uid-1")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	var rows []sqliteRecord
+	require.NoError(t, sink.DB.Order("time").Find(&rows).Error)
+	require.Len(t, rows, 2, "GET is not audited")
+
+	put := rows[0]
+	assert.Equal(t, "uid-1", put.Actor)
+	assert.Equal(t, "alice", put.ActorName)
+	assert.Equal(t, "/projects/:id", put.Route)
+	assert.Equal(t, "42", put.ResourceID)
+	assert.Equal(t, "192.0.2.1", put.ClientIP)
+	assert.Equal(t, OutcomeSuccess, put.Outcome)
+	assert.JSONEq(t, `{"name":{"before":"old","after":"new"}}`, put.Diff)
+
+	assert.Equal(t, OutcomeDenied, rows[1].Outcome)
+	assert.Equal(t, http.StatusForbidden, rows[1].Status)
+}
+
+func TestDiff(t *testing.T) {
+	d := Diff(nil, project{Name: "a"})
+	assert.Equal(t, Change{After: "a"}, d["name"])
+	assert.Empty(t, Diff(project{Name: "a"}, project{Name: "a"}))
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/ergoapi/util/exsink"
+	"github.com/ergoapi/util/log/hooks/file"
+
+	"github.com/glebarez/sqlite"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// LogrusSink 通过 logrus 输出审计记录, 实际投递由 logger 上的 hook 和 formatter 决定
+type LogrusSink struct {
+	Logger *logrus.Logger
+}
+
+func NewLogrusSink(l *logrus.Logger) *LogrusSink {
+	if l == nil {
+		l = logrus.StandardLogger()
+	}
+	return &LogrusSink{Logger: l}
+}
+
+func (s *LogrusSink) Write(ctx context.Context, r *Record) error {
+	fields := logrus.Fields{
+		"Tag":         "audit",
+		"audit_id":    r.ID,
+		"actor":       r.Actor,
+		"method":      r.Method,
+		"route":       r.Route,
+		"path":        r.Path,
+		"resource_id": r.ResourceID,
+		"client_ip":   r.ClientIP,
+		"traceID":     r.TraceID,
+		"status":      r.Status,
+		"outcome":     r.Outcome,
+	}
+	if r.ActorName != "" {
+		fields["actor_name"] = r.ActorName
+	}
+	if r.Error != "" {
+		fields["error"] = r.Error
+	}
+	if len(r.Diff) > 0 {
+		fields["diff"] = r.Diff
+	}
+	if len(r.Extra) > 0 {
+		fields["extra"] = r.Extra
+	}
+	s.Logger.WithContext(ctx).WithTime(r.Time).WithFields(fields).Info("audit")
+	return nil
+}
+
+// NewFileSink 将审计记录以 JSON 行写入独立的轮转文件, 不输出到控制台
+func NewFileSink(cfg file.RotateFileConfig) (*LogrusSink, error) {
+	if cfg.Level == logrus.PanicLevel && len(cfg.Levels) == 0 {
+		cfg.Level = logrus.InfoLevel
+	}
+	hook, err := file.NewRotateFileHook(cfg)
+	if err != nil {
+		return nil, err
+	}
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	l.SetLevel(logrus.InfoLevel)
+	l.AddHook(hook)
+	return NewLogrusSink(l), nil
+}
+
+// EventSink 通过 exsink.EventSink 投递审计记录
+type EventSink struct {
+	Sink exsink.EventSink
+}
+
+func NewEventSink(s exsink.EventSink) *EventSink {
+	return &EventSink{Sink: s}
+}
+
+func (s *EventSink) Write(_ context.Context, r *Record) error {
+	return s.Sink.SendEvent(r)
+}
+
+// sqliteRecord 审计表结构, 只插入不更新
+type sqliteRecord struct {
+	ID         string    `gorm:"primaryKey;size:36"`
+	Time       time.Time `gorm:"index"`
+	Actor      string    `gorm:"index;size:128"`
+	ActorName  string    `gorm:"size:128"`
+	Method     string    `gorm:"size:16"`
+	Route      string    `gorm:"index;size:255"`
+	Path       string    `gorm:"size:1024"`
+	ResourceID string    `gorm:"index;size:255"`
+	ClientIP   string    `gorm:"size:64"`
+	UserAgent  string    `gorm:"size:512"`
+	TraceID    string    `gorm:"size:64"`
+	Status     int
+	Outcome    string `gorm:"size:16"`
+	Error      string
+	Diff       string
+	Extra      string
+}
+
+func (sqliteRecord) TableName() string {
+	return "audit_records"
+}
+
+// SQLiteSink 将审计记录写入 SQLite
+type SQLiteSink struct {
+	DB *gorm.DB
+}
+
+// NewSQLiteSink 打开(或创建) dsn 对应的 SQLite 数据库并建表
+func NewSQLiteSink(dsn string) (*SQLiteSink, error) {
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		return nil, errors.Wrap(err, "audit: open sqlite")
+	}
+	if err := db.AutoMigrate(&sqliteRecord{}); err != nil {
+		return nil, errors.Wrap(err, "audit: migrate sqlite")
+	}
+	return &SQLiteSink{DB: db}, nil
+}
+
+func (s *SQLiteSink) Write(ctx context.Context, r *Record) error {
+	row := sqliteRecord{
+		ID:         r.ID,
+		Time:       r.Time,
+		Actor:      r.Actor,
+		ActorName:  r.ActorName,
+		Method:     r.Method,
+		Route:      r.Route,
+		Path:       r.Path,
+		ResourceID: r.ResourceID,
+		ClientIP:   r.ClientIP,
+		UserAgent:  r.UserAgent,
+		TraceID:    r.TraceID,
+		Status:     r.Status,
+		Outcome:    r.Outcome,
+		Error:      r.Error,
+	}
+	if len(r.Diff) > 0 {
+		b, _ := json.Marshal(r.Diff)
+		row.Diff = string(b)
+	}
+	if len(r.Extra) > 0 {
+		b, _ := json.Marshal(r.Extra)
+		row.Extra = string(b)
+	}
+	return s.DB.WithContext(ctx).Create(&row).Error
+}
+
+// MultiSink 依次写入多个 sink, 返回所有错误
+type MultiSink []Sink
+
+func (m MultiSink) Write(ctx context.Context, r *Record) error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Write(ctx, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
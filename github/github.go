@@ -43,10 +43,16 @@ type DefaultClient struct {
 
 // NewDefaultClient 创建默认客户端
 func NewDefaultClient() *DefaultClient {
+	return NewClient(&http.Client{
+		Timeout: time.Second * 10,
+	})
+}
+
+// NewClient 使用指定的 http.Client 创建客户端
+// 需要传递 trace 时可传入 exhttp.WithTracing 包装后的 client
+func NewClient(httpClient *http.Client) *DefaultClient {
 	return &DefaultClient{
-		client: github.NewClient(&http.Client{
-			Timeout: time.Second * 10,
-		}),
+		client: github.NewClient(httpClient),
 	}
 }
 
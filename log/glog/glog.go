@@ -68,16 +68,10 @@ func (mgl *GLogger) LogMode(logLevel logger.LogLevel) logger.Interface {
 
 // logWithLevel 是一个辅助函数，用于处理通用的日志格式化和输出逻辑
 func (mgl *GLogger) logWithLevel(ctx context.Context, level logrus.Level, message string, values ...any) {
-	trace := exctx.GetTraceContext(ctx)
 	msg := fmt.Sprintf("message=%+v||values=%+v", message, fmt.Sprint(values...))
 	msg = strings.Trim(fmt.Sprintf("%q", msg), "\"")
 
-	entry := logrus.WithFields(logrus.Fields{
-		"traceID":     trace.TraceID,
-		"SpanID":      trace.SpanID,
-		"childSpanID": trace.CSpanID,
-		"Tag":         "gorm",
-	})
+	entry := exctx.Logger(ctx).WithField("Tag", "gorm")
 
 	switch level {
 	case logrus.InfoLevel:
@@ -101,18 +95,14 @@ func (mgl *GLogger) Error(ctx context.Context, message string, values ...any) {
 	mgl.logWithLevel(ctx, logrus.ErrorLevel, message, values...)
 }
 
-// createTraceFields 创建通用的 trace 字段
-func createTraceFields(trace *exctx.TraceContext, begin time.Time, elapsed time.Duration, sql string, rows int64) logrus.Fields {
-	fields := logrus.Fields{
-		"traceID":         trace.TraceID,
-		"SpanID":          trace.SpanID,
-		"childSpanID":     trace.CSpanID,
-		"Tag":             "gorm",
-		"FileWithLineNum": getFilteredFileWithLineNum(),
-		"current_time":    begin.Format(time.RFC3339),
-		"proc_time":       elapsed.Milliseconds(),
-		"sql":             sql,
-	}
+// createTraceFields 在 ctx 日志字段(trace、用户等)基础上添加 SQL 字段
+func createTraceFields(ctx context.Context, begin time.Time, elapsed time.Duration, sql string, rows int64) logrus.Fields {
+	fields := exctx.LogFields(ctx)
+	fields["Tag"] = "gorm"
+	fields["FileWithLineNum"] = getFilteredFileWithLineNum()
+	fields["current_time"] = begin.Format(time.RFC3339)
+	fields["proc_time"] = elapsed.Milliseconds()
+	fields["sql"] = sql
 
 	if rows == -1 {
 		fields["rows"] = "-"
@@ -124,19 +114,36 @@ func createTraceFields(trace *exctx.TraceContext, begin time.Time, elapsed time.
 }
 
 func (mgl *GLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	// fc 会渲染 SQL, span 与日志共用一次结果
+	render := fc
+	var (
+		sql      string
+		rows     int64
+		rendered bool
+	)
+	fc = func() (string, int64) {
+		if !rendered {
+			sql, rows = render()
+			rendered = true
+		}
+		return sql, rows
+	}
+	if exctx.TracingEnabled() {
+		emitSpan(ctx, begin, fc, err)
+	}
+
 	// 静默模式直接返回
 	if mgl.LogLevel == logger.Silent {
 		return
 	}
 
-	trace := exctx.GetTraceContext(ctx)
 	elapsed := time.Since(begin)
 
 	// 根据不同情况记录日志
 	switch {
 	case err != nil && mgl.LogLevel >= logger.Error:
 		sql, rows := fc()
-		fields := createTraceFields(trace, begin, elapsed, sql, rows)
+		fields := createTraceFields(ctx, begin, elapsed, sql, rows)
 
 		// ErrRecordNotFound 或未影响行数时用 Warn，否则用 Error
 		if errors.Is(err, gorm.ErrRecordNotFound) || rows == -1 || rows == 0 {
@@ -147,14 +154,29 @@ func (mgl *GLogger) Trace(ctx context.Context, begin time.Time, fc func() (strin
 
 	case mgl.SlowThreshold != 0 && elapsed > mgl.SlowThreshold && mgl.LogLevel >= logger.Warn:
 		sql, rows := fc()
-		fields := createTraceFields(trace, begin, elapsed, sql, rows)
+		fields := createTraceFields(ctx, begin, elapsed, sql, rows)
 		fields["slowlog"] = fmt.Sprintf("SLOW SQL >= %v", mgl.SlowThreshold)
 		logrus.WithFields(fields).Warn("slow query executed")
 
 	case mgl.LogLevel >= logger.Info:
 		sql, rows := fc()
-		fields := createTraceFields(trace, begin, elapsed, sql, rows)
+		fields := createTraceFields(ctx, begin, elapsed, sql, rows)
 		// 正常执行的SQL，msg字段留空
 		logrus.WithFields(fields).Info("")
 	}
 }
+
+// emitSpan 为一次 SQL 执行补记 client span
+func emitSpan(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, rows := fc()
+	_, span := exctx.StartSpan(ctx, "gorm.query", exctx.WithSpanKind(exctx.SpanKindClient), exctx.WithStartTime(begin))
+	span.SetAttributes(map[string]any{
+		"db.statement": sql,
+		"db.rows":      rows,
+		"code.caller":  getFilteredFileWithLineNum(),
+	})
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.RecordError(err)
+	}
+	span.End()
+}
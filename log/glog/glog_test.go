@@ -325,3 +325,29 @@ func TestGLogger_Integration(t *testing.T) {
 	// slowsqlFiles, _ := filepath.Glob(filepath.Join(tmpDir, "*.slowsql.log"))
 	// assert.NotEmpty(t, slowsqlFiles)
 }
+
+func TestGLogger_TraceEmitsSpan(t *testing.T) {
+	exp := exctx.NewMemoryExporter()
+	exctx.SetExporters(exp)
+	defer exctx.SetExporters()
+
+	ctx, parent := exctx.StartSpan(context.Background(), "handler")
+	calls := 0
+	fc := func() (string, int64) {
+		calls++
+		return "SELECT * FROM users", 3
+	}
+	gl := &GLogger{LogLevel: logger.Info}
+	gl.Trace(ctx, time.Now().Add(-5*time.Millisecond), fc, errors.New("db down"))
+	(&GLogger{LogLevel: logger.Silent}).Trace(ctx, time.Now(), fc, nil)
+
+	spans := exp.Spans()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "gorm.query", spans[0].Name)
+	assert.Equal(t, parent.SpanID(), spans[0].ParentSpanID)
+	assert.Equal(t, "SELECT * FROM users", spans[0].Attributes["db.statement"])
+	assert.Equal(t, exctx.StatusError, spans[0].StatusCode)
+	assert.GreaterOrEqual(t, spans[0].Duration(), 5*time.Millisecond)
+	assert.Equal(t, exctx.StatusUnset, spans[1].StatusCode)
+	assert.Equal(t, 2, calls, "SQL is rendered once per Trace call")
+}
@@ -0,0 +1,306 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+// Package async 将耗时的 logrus hook(如 TGHook)放到后台队列执行, 避免阻塞打日志的 goroutine
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+// DropPolicy 队列满时的处理策略
+type DropPolicy int
+
+const (
+	// DropNewest 丢弃新日志, 默认
+	DropNewest DropPolicy = iota
+	// DropOldest 丢弃队列中最早的日志
+	DropOldest
+	// Block 阻塞等待队列空位
+	Block
+)
+
+// 丢弃原因, 对应 prometheus 的 reason 标签
+const (
+	reasonQueueFull = "queue_full"
+	reasonClosed    = "closed"
+	reasonShutdown  = "shutdown"
+)
+
+var (
+	promOnce    sync.Once
+	promDropped *prometheus.CounterVec
+	promErrors  *prometheus.CounterVec
+)
+
+// registerMetrics 首次调用 New 时向默认 registry 注册指标, 仅 import 本包不会触碰 registry
+// 同名指标已注册时复用已有的 collector
+func registerMetrics() {
+	promOnce.Do(func() {
+		promDropped = registerOrReuse(prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "exlog",
+				Subsystem: "async_hook",
+				Name:      "dropped_total",
+				Help:      "log entries dropped by async hook",
+			}, []string{"hook", "reason"},
+		))
+		promErrors = registerOrReuse(prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "exlog",
+				Subsystem: "async_hook",
+				Name:      "errors_total",
+				Help:      "errors returned by the wrapped hook",
+			}, []string{"hook"},
+		))
+	})
+}
+
+func registerOrReuse[T prometheus.Collector](c T) T {
+	if err := prometheus.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
+
+// BatchHook 可一次处理多条日志的 hook, 实现后按批调用 FireBatch, 否则逐条调用 Fire
+type BatchHook interface {
+	logrus.Hook
+	FireBatch(entries []*logrus.Entry) error
+}
+
+// Options AsyncHook 配置
+type Options struct {
+	// Name prometheus hook 标签, 默认为被包装 hook 的类型名
+	Name string
+	// QueueSize 队列长度, 默认 1024
+	QueueSize int
+	// Workers 后台 worker 数, 默认 1; 大于 1 时不保证日志顺序
+	Workers int
+	// BatchSize 每批最多条数, 默认 1
+	BatchSize int
+	// FlushInterval 凑批的最长等待时间, 默认 1s, 仅 BatchSize 大于 1 时生效
+	FlushInterval time.Duration
+	// Policy 队列满时的策略, 默认 DropNewest
+	Policy DropPolicy
+	// OnError 被包装 hook 返回错误时调用, 默认输出到 stderr
+	OnError func(err error)
+}
+
+// AsyncHook 带有界队列的异步 hook, 退出前需调用 Shutdown 发送剩余日志
+type AsyncHook struct {
+	hook    logrus.Hook
+	opts    Options
+	queue   chan *logrus.Entry
+	mu      sync.RWMutex
+	closed  bool
+	stop    chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+	dropped atomic.Uint64
+}
+
+// New 包装 hook 并启动 worker
+func New(hook logrus.Hook, opts *Options) *AsyncHook {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Name == "" {
+		o.Name = fmt.Sprintf("%T", hook)
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 1024
+	}
+	if o.Workers <= 0 {
+		o.Workers = 1
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 1
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = time.Second
+	}
+	if o.OnError == nil {
+		o.OnError = func(err error) {
+			fmt.Fprintf(os.Stderr, "async hook %s: %v\n", o.Name, err)
+		}
+	}
+	registerMetrics()
+	h := &AsyncHook{
+		hook:  hook,
+		opts:  o,
+		queue: make(chan *logrus.Entry, o.QueueSize),
+		stop:  make(chan struct{}),
+	}
+	for i := 0; i < o.Workers; i++ {
+		h.wg.Add(1)
+		go h.worker()
+	}
+	return h
+}
+
+func (h *AsyncHook) Levels() []logrus.Level {
+	return h.hook.Levels()
+}
+
+// Fire 将 entry 的副本放入队列, 不会返回被包装 hook 的错误
+func (h *AsyncHook) Fire(entry *logrus.Entry) error {
+	// logrus 在 Fire 返回后会复用/修改 entry, 需要复制
+	e := *entry
+	e.Data = maps.Clone(entry.Data)
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		h.drop(reasonClosed, 1)
+		return nil
+	}
+	switch h.opts.Policy {
+	case Block:
+		select {
+		case h.queue <- &e:
+		case <-h.stop:
+			h.drop(reasonClosed, 1)
+		}
+	case DropOldest:
+		for {
+			select {
+			case h.queue <- &e:
+				return nil
+			default:
+			}
+			select {
+			case <-h.queue:
+				h.drop(reasonQueueFull, 1)
+			default:
+			}
+		}
+	default:
+		select {
+		case h.queue <- &e:
+		default:
+			h.drop(reasonQueueFull, 1)
+		}
+	}
+	return nil
+}
+
+// Dropped 累计丢弃的日志条数
+func (h *AsyncHook) Dropped() uint64 {
+	return h.dropped.Load()
+}
+
+// Len 队列中待处理的条数
+func (h *AsyncHook) Len() int {
+	return len(h.queue)
+}
+
+func (h *AsyncHook) drop(reason string, n int) {
+	h.dropped.Add(uint64(n))
+	promDropped.WithLabelValues(h.opts.Name, reason).Add(float64(n))
+}
+
+// Shutdown 停止接收新日志并等待队列处理完毕; ctx 到期时返回 ctx.Err(),
+// 未处理的日志计入 dropped, worker 处理完当前批次后退出
+func (h *AsyncHook) Shutdown(ctx context.Context) error {
+	// 先唤醒 Block 策略下等待的 Fire, 才能拿到写锁
+	h.once.Do(func() { close(h.stop) })
+	h.mu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.queue)
+	}
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		// 取走剩余日志, 让 worker 尽快退出
+		n := 0
+		for range h.queue {
+			n++
+		}
+		h.drop(reasonShutdown, n)
+		return ctx.Err()
+	}
+}
+
+func (h *AsyncHook) worker() {
+	defer h.wg.Done()
+	batch := make([]*logrus.Entry, 0, h.opts.BatchSize)
+	for {
+		e, ok := <-h.queue
+		if !ok {
+			return
+		}
+		batch = append(batch[:0], e)
+		ok = h.fill(&batch)
+		h.fire(batch)
+		if !ok {
+			return
+		}
+	}
+}
+
+// fill 在 FlushInterval 内凑满一批, 队列关闭时返回 false
+func (h *AsyncHook) fill(batch *[]*logrus.Entry) bool {
+	if h.opts.BatchSize <= 1 {
+		return true
+	}
+	timer := time.NewTimer(h.opts.FlushInterval)
+	defer timer.Stop()
+	for len(*batch) < h.opts.BatchSize {
+		select {
+		case e, ok := <-h.queue:
+			if !ok {
+				return false
+			}
+			*batch = append(*batch, e)
+		case <-timer.C:
+			return true
+		}
+	}
+	return true
+}
+
+func (h *AsyncHook) fire(batch []*logrus.Entry) {
+	if bh, ok := h.hook.(BatchHook); ok {
+		if err := bh.FireBatch(batch); err != nil {
+			promErrors.WithLabelValues(h.opts.Name).Inc()
+			h.opts.OnError(err)
+		}
+		return
+	}
+	for _, e := range batch {
+		if err := h.hook.Fire(e); err != nil {
+			promErrors.WithLabelValues(h.opts.Name).Inc()
+			h.opts.OnError(err)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package async
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordHook 记录收到的日志, release 关闭前阻塞
+type recordHook struct {
+	mu      sync.Mutex
+	msgs    []string
+	batches []int
+	release chan struct{}
+	err     error
+}
+
+func newRecordHook(blocked bool) *recordHook {
+	h := &recordHook{release: make(chan struct{})}
+	if !blocked {
+		close(h.release)
+	}
+	return h
+}
+
+func (h *recordHook) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (h *recordHook) Fire(e *logrus.Entry) error {
+	<-h.release
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.msgs = append(h.msgs, e.Message)
+	return h.err
+}
+
+func (h *recordHook) messages() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.msgs...)
+}
+
+type batchHook struct{ recordHook }
+
+func (h *batchHook) FireBatch(entries []*logrus.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.batches = append(h.batches, len(entries))
+	for _, e := range entries {
+		h.msgs = append(h.msgs, e.Message)
+	}
+	return nil
+}
+
+func newLogger(h logrus.Hook) *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.AddHook(h)
+	return logger
+}
+
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	m := &dto.Metric{}
+	require.NoError(t, c.Write(m))
+	return m.GetCounter().GetValue()
+}
+
+func TestAsyncHookFlushOnShutdown(t *testing.T) {
+	inner := newRecordHook(false)
+	registerMetrics()
+	closed := promDropped.WithLabelValues("flush", reasonClosed)
+	before := counterValue(t, closed)
+	h := New(inner, &Options{Name: "flush"})
+	logger := newLogger(h)
+
+	logger.WithField("k", "v").Info("a")
+	logger.Warn("b")
+	require.NoError(t, h.Shutdown(context.Background()))
+	assert.Equal(t, []string{"a", "b"}, inner.messages())
+
+	// 关闭后的日志被丢弃而不是 panic
+	logger.Info("late")
+	assert.Equal(t, uint64(1), h.Dropped())
+	assert.Equal(t, before+1, counterValue(t, closed))
+}
+
+func TestAsyncHookDropPolicies(t *testing.T) {
+	tests := []struct {
+		policy DropPolicy
+		want   []string
+	}{
+		{DropNewest, []string{"0", "1", "2"}},
+		{DropOldest, []string{"0", "3", "4"}},
+	}
+	for _, tt := range tests {
+		inner := newRecordHook(true)
+		h := New(inner, &Options{Name: "policy", QueueSize: 2, Policy: tt.policy})
+		logger := newLogger(h)
+
+		logger.Info("0")
+		// 等 worker 取走第一条并阻塞在 Fire 中
+		require.Eventually(t, func() bool { return h.Len() == 0 }, time.Second, time.Millisecond)
+		for _, m := range []string{"1", "2", "3", "4"} {
+			logger.Info(m)
+		}
+		assert.Equal(t, uint64(2), h.Dropped())
+
+		close(inner.release)
+		require.NoError(t, h.Shutdown(context.Background()))
+		assert.Equal(t, tt.want, inner.messages(), "policy %d", tt.policy)
+	}
+}
+
+func TestAsyncHookBlock(t *testing.T) {
+	inner := newRecordHook(true)
+	h := New(inner, &Options{QueueSize: 1, Policy: Block})
+	logger := newLogger(h)
+
+	logger.Info("0")
+	require.Eventually(t, func() bool { return h.Len() == 0 }, time.Second, time.Millisecond)
+	logger.Info("1")
+
+	done := make(chan struct{})
+	go func() {
+		logger.Info("2")
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Fire should block while the queue is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(inner.release)
+	<-done
+	require.NoError(t, h.Shutdown(context.Background()))
+	assert.Equal(t, []string{"0", "1", "2"}, inner.messages())
+	assert.Zero(t, h.Dropped())
+}
+
+func TestAsyncHookShutdownTimeout(t *testing.T) {
+	inner := newRecordHook(true)
+	defer close(inner.release)
+	registerMetrics()
+	shutdown := promDropped.WithLabelValues("timeout", reasonShutdown)
+	before := counterValue(t, shutdown)
+	h := New(inner, &Options{Name: "timeout", QueueSize: 8})
+	logger := newLogger(h)
+	for range 4 {
+		logger.Info("x")
+	}
+	require.Eventually(t, func() bool { return h.Len() == 3 }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := h.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	// 一条在 worker 中, 其余三条丢弃
+	assert.Equal(t, uint64(3), h.Dropped())
+	assert.Equal(t, before+3, counterValue(t, shutdown))
+}
+
+func TestAsyncHookBatch(t *testing.T) {
+	inner := &batchHook{recordHook: *newRecordHook(false)}
+	h := New(inner, &Options{BatchSize: 3, FlushInterval: 20 * time.Millisecond})
+	logger := newLogger(h)
+	for _, m := range []string{"a", "b", "c", "d"} {
+		logger.Info(m)
+	}
+	// 第二批不足 3 条, 由 FlushInterval 触发
+	require.Eventually(t, func() bool { return len(inner.messages()) == 4 }, time.Second, time.Millisecond)
+	require.NoError(t, h.Shutdown(context.Background()))
+	assert.Equal(t, []int{3, 1}, inner.batches)
+}
+
+func TestAsyncHookErrors(t *testing.T) {
+	inner := newRecordHook(false)
+	inner.err = errors.New("telegram down")
+	var got []error
+	var mu sync.Mutex
+	registerMetrics()
+	errCounter := promErrors.WithLabelValues("errors")
+	before := counterValue(t, errCounter)
+	h := New(inner, &Options{Name: "errors", OnError: func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, err)
+	}})
+	logger := newLogger(h)
+	logger.Error("boom")
+	require.NoError(t, h.Shutdown(context.Background()))
+	assert.Len(t, got, 1)
+	assert.Equal(t, before+1, counterValue(t, errCounter))
+}
+
+func TestRegisterOrReuse(t *testing.T) {
+	registerMetrics()
+	// 重复注册同名指标时复用已有的 collector
+	dup := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "exlog",
+		Subsystem: "async_hook",
+		Name:      "errors_total",
+		Help:      "errors returned by the wrapped hook",
+	}, []string{"hook"})
+	assert.Same(t, promErrors, registerOrReuse(dup))
+}
@@ -27,6 +27,7 @@ type TGConfig struct {
 	ChatID int64
 }
 
+// NewTGHook 创建 TGHook, Fire 会同步发送消息, 建议用 async.New 包装以免阻塞打日志的 goroutine
 func NewTGHook(cfg TGConfig) (*TGHook, error) {
 	tbcfg := tb.Settings{
 		Token:  cfg.Token,
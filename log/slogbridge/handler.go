@@ -0,0 +1,131 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+// Package slogbridge 在 log/slog 与 logrus 之间互相转发日志, 双向保留 exctx 中的 trace 字段
+package slogbridge
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"github.com/ergoapi/util/exctx"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Handler 将 slog 记录转发给 logrus logger 的 slog.Handler.
+// logger 上的 hooks、formatter 照常生效; 开启 ReportCaller 时调用者取自 slog 记录,
+// 因此 formatter.FilteredTextFormatter 等按调用者过滤的逻辑不受桥接层影响.
+// ctx 中的 exctx 字段(traceID、SpanID、user 等)会写入 entry, 同名时以 slog 属性为准
+type Handler struct {
+	logger *logrus.Logger
+	fields logrus.Fields
+	group  string
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+// NewHandler 创建 Handler, logger 为 nil 时使用 logrus 标准 logger
+func NewHandler(logger *logrus.Logger) *Handler {
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+	return &Handler{logger: logger}
+}
+
+// NewLogger 返回输出到 logger 的 *slog.Logger
+func NewLogger(logger *logrus.Logger) *slog.Logger {
+	return slog.New(NewHandler(logger))
+}
+
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.IsLevelEnabled(toLogrusLevel(level))
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	data := exctx.LogFields(ctx)
+	for k, v := range h.fields {
+		data[k] = v
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(data, h.group, a)
+		return true
+	})
+
+	entry := &logrus.Entry{
+		Logger:  h.logger,
+		Data:    data,
+		Time:    r.Time,
+		Context: ctx,
+	}
+	if h.logger.ReportCaller && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		entry.Caller = &frame
+	}
+	// 走 logrus 自身的写入路径: hooks、formatter 与 logger.Out 的写锁都与直接调用 logrus 一致,
+	// 预先设置的 Caller 会被保留
+	entry.Log(toLogrusLevel(r.Level), r.Message)
+	return nil
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	fields := make(logrus.Fields, len(h.fields)+len(attrs))
+	for k, v := range h.fields {
+		fields[k] = v
+	}
+	for _, a := range attrs {
+		addAttr(fields, h.group, a)
+	}
+	return &Handler{logger: h.logger, fields: fields, group: h.group}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &Handler{logger: h.logger, fields: h.fields, group: h.group + name + "."}
+}
+
+// addAttr 将 attr 展开写入 fields, group 以 "." 连接为 key 前缀
+func addAttr(fields logrus.Fields, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return
+		}
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range attrs {
+			addAttr(fields, prefix, ga)
+		}
+		return
+	}
+	fields[prefix+a.Key] = a.Value.Any()
+}
+
+func toLogrusLevel(level slog.Level) logrus.Level {
+	switch {
+	case level >= slog.LevelError:
+		return logrus.ErrorLevel
+	case level >= slog.LevelWarn:
+		return logrus.WarnLevel
+	case level >= slog.LevelInfo:
+		return logrus.InfoLevel
+	case level >= slog.LevelDebug:
+		return logrus.DebugLevel
+	default:
+		return logrus.TraceLevel
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package slogbridge
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+
+	"github.com/ergoapi/util/exctx"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Hook 将 logrus entry 写入 slog.Handler 的 hook, 适合在保留原输出的同时接入 slog 生态.
+// entry.Context 会传给 handler, 其中的 exctx 字段在 entry 未设置同名字段时作为属性写入.
+// 注意不要将 Hook 指向转发回同一 logrus logger 的 Handler, 否则会循环
+type Hook struct {
+	Handler   slog.Handler
+	LogLevels []logrus.Level
+}
+
+// NewHook 创建 Hook, 不指定 levels 时处理全部级别
+func NewHook(h slog.Handler, levels ...logrus.Level) *Hook {
+	if len(levels) == 0 {
+		levels = logrus.AllLevels
+	}
+	return &Hook{Handler: h, LogLevels: levels}
+}
+
+func (h *Hook) Levels() []logrus.Level {
+	return h.LogLevels
+}
+
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	return handleEntry(h.Handler, entry)
+}
+
+// Formatter 将 entry 交给 slog.Handler 输出的 logrus formatter, 自身不产生字节,
+// 用于把 logrus logger 的输出整体切换到 slog, 可配合 logger.SetOutput(io.Discard) 使用
+type Formatter struct {
+	Handler slog.Handler
+}
+
+// NewFormatter 创建 Formatter
+func NewFormatter(h slog.Handler) *Formatter {
+	return &Formatter{Handler: h}
+}
+
+func (f *Formatter) Format(entry *logrus.Entry) ([]byte, error) {
+	return nil, handleEntry(f.Handler, entry)
+}
+
+func handleEntry(h slog.Handler, entry *logrus.Entry) error {
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	level := toSlogLevel(entry.Level)
+	if !h.Enabled(ctx, level) {
+		return nil
+	}
+	var pc uintptr
+	if entry.Caller != nil {
+		pc = entry.Caller.PC
+	}
+	r := slog.NewRecord(entry.Time, level, entry.Message, pc)
+
+	fields := exctx.LogFields(ctx)
+	for k, v := range entry.Data {
+		fields[k] = v
+	}
+	// 按 key 排序, 输出稳定
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		r.AddAttrs(slog.Any(k, fields[k]))
+	}
+	return h.Handle(ctx, r)
+}
+
+func toSlogLevel(level logrus.Level) slog.Level {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return slog.LevelError + 4
+	case logrus.ErrorLevel:
+		return slog.LevelError
+	case logrus.WarnLevel:
+		return slog.LevelWarn
+	case logrus.InfoLevel:
+		return slog.LevelInfo
+	case logrus.DebugLevel:
+		return slog.LevelDebug
+	default:
+		return slog.LevelDebug - 4
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2025-2025 All rights reserved.
+//
+// The original source code is licensed under the DO WHAT THE FUCK YOU WANT TO PUBLIC LICENSE.
+//
+// You may review the terms of licenses in the LICENSE file.
+
+package slogbridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ergoapi/util/exctx"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerForwardsToLogrus(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.SetReportCaller(true)
+	hook := test.NewLocal(logger)
+
+	ctx, span := exctx.StartSpan(context.Background(), "op")
+	ctx = exctx.WithUser(ctx, "alice")
+
+	log := NewLogger(logger).With("component", "billing").WithGroup("req")
+	log.InfoContext(ctx, "charged", "amount", 42, slog.Group("card", "last4", "4242"))
+	log.Debug("hidden")
+
+	require.Len(t, hook.AllEntries(), 1)
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	assert.Equal(t, "charged", out["msg"])
+	assert.Equal(t, "info", out["level"])
+	assert.Equal(t, span.TraceID(), out["traceID"])
+	assert.Equal(t, span.SpanID(), out["SpanID"])
+	assert.Equal(t, "alice", out["user"])
+	assert.Equal(t, "billing", out["component"])
+	assert.Equal(t, float64(42), out["req.amount"])
+	assert.Equal(t, "4242", out["req.card.last4"])
+	// 调用者是 slog 的调用方, 而不是桥接层
+	assert.Contains(t, out["file"], "slogbridge_test.go")
+}
+
+func TestHandlerLevels(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	logger.SetLevel(logrus.TraceLevel)
+	log := NewLogger(logger)
+	log.Log(context.Background(), slog.LevelDebug-4, "trace")
+	log.Debug("debug")
+	log.Warn("warn")
+	log.Error("error", "err", errors.New("boom"))
+
+	levels := []logrus.Level{}
+	for _, e := range hook.AllEntries() {
+		levels = append(levels, e.Level)
+	}
+	assert.Equal(t, []logrus.Level{logrus.TraceLevel, logrus.DebugLevel, logrus.WarnLevel, logrus.ErrorLevel}, levels)
+	assert.EqualError(t, hook.LastEntry().Data["err"].(error), "boom")
+}
+
+func TestHookWritesToSlog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.AddHook(NewHook(slog.NewJSONHandler(&buf, nil), logrus.InfoLevel, logrus.WarnLevel))
+
+	ctx, span := exctx.StartSpan(context.Background(), "op")
+	logger.WithContext(ctx).WithField("order", 7).Warn("slow")
+	logger.Debug("skipped")
+
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	assert.Equal(t, "WARN", out["level"])
+	assert.Equal(t, "slow", out["msg"])
+	assert.Equal(t, float64(7), out["order"])
+	assert.Equal(t, span.TraceID(), out["traceID"])
+}
+
+func TestFormatterWritesToSlog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetFormatter(NewFormatter(slog.NewTextHandler(&buf, nil)))
+
+	ctx := exctx.WithTenant(context.Background(), "acme")
+	logger.WithContext(ctx).WithField("tenant", "override").Error("failed")
+
+	line := buf.String()
+	assert.Equal(t, 1, strings.Count(line, "\n"))
+	assert.Contains(t, line, "level=ERROR")
+	assert.Contains(t, line, "msg=failed")
+	assert.Contains(t, line, "tenant=override")
+}
+
+func TestHandlerSharesLogrusWriteLock(t *testing.T) {
+	// bytes.Buffer 非并发安全, 桥接层与 logrus 的写入必须共用同一把锁
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	log := NewLogger(logger)
+
+	const n = 100
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			logger.Info("logrus")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; i++ {
+			log.Info("slog")
+		}
+	}()
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2*n)
+	for _, line := range lines {
+		assert.True(t, json.Valid([]byte(line)), line)
+	}
+}